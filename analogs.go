@@ -0,0 +1,266 @@
+package tehnomir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	ANALOG_MAX_DEPTH = 2
+	ANALOG_MAX_NODES = 500
+)
+
+type AnalogNode struct {
+	ProductID      int     `json:"productId"`
+	BrandID        int     `json:"brandId,omitempty"` // 0, если бренд не найден в BrandRegistry
+	Brand          string  `json:"brand"`
+	Code           string  `json:"code"`
+	DescriptionRus string  `json:"descriptionRus,omitempty"`
+	DescriptionUa  string  `json:"descriptionUa,omitempty"`
+	Weight         float64 `json:"weight,omitempty"`
+	Volume         float64 `json:"volume,omitempty"`
+	Depth          int     `json:"depth"`
+	Expanded       bool    `json:"expanded"` // аналоги узла запрошены
+}
+
+type AnalogEdge struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// AnalogGraph граф взаимозаменяемости деталей, узлы по ProductID, ребра ненаправленные.
+type AnalogGraph struct {
+	Root  int
+	Nodes map[int]*AnalogNode
+	adj   map[int]map[int]bool
+}
+
+func newAnalogGraph() *AnalogGraph {
+	return &AnalogGraph{
+		Nodes: make(map[int]*AnalogNode),
+		adj:   make(map[int]map[int]bool),
+	}
+}
+
+func (g *AnalogGraph) addEdge(a, b int) {
+	if a == b {
+		return
+	}
+	if g.adj[a] == nil {
+		g.adj[a] = make(map[int]bool)
+	}
+	if g.adj[b] == nil {
+		g.adj[b] = make(map[int]bool)
+	}
+	g.adj[a][b] = true
+	g.adj[b][a] = true
+}
+
+// Neighbors прямые аналоги детали.
+func (g *AnalogGraph) Neighbors(productID int) []*AnalogNode {
+	var nodes []*AnalogNode
+	for id := range g.adj[productID] {
+		nodes = append(nodes, g.Nodes[id])
+	}
+	sortNodes(nodes)
+	return nodes
+}
+
+// Interchangeable все детали, связанные с productID через аналоги любой глубины.
+func (g *AnalogGraph) Interchangeable(productID int) []*AnalogNode {
+	seen := map[int]bool{productID: true}
+	queue := []int{productID}
+	var nodes []*AnalogNode
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for next := range g.adj[id] {
+			if seen[next] {
+				continue
+			}
+			seen[next] = true
+			nodes = append(nodes, g.Nodes[next])
+			queue = append(queue, next)
+		}
+	}
+	sortNodes(nodes)
+	return nodes
+}
+
+// Find ищет узел по бренду и номеру.
+func (g *AnalogGraph) Find(brand, code string) (*AnalogNode, bool) {
+	for _, n := range g.Nodes {
+		if NormalizeBrandName(n.Brand) == NormalizeBrandName(brand) && normCode(n.Code) == normCode(code) {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// Edges ребра графа, каждое один раз.
+func (g *AnalogGraph) Edges() []AnalogEdge {
+	var edges []AnalogEdge
+	for a, bs := range g.adj {
+		for b := range bs {
+			if a < b {
+				edges = append(edges, AnalogEdge{From: a, To: b})
+			}
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
+func (g *AnalogGraph) sortedNodes() []*AnalogNode {
+	nodes := make([]*AnalogNode, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		nodes = append(nodes, n)
+	}
+	sortNodes(nodes)
+	return nodes
+}
+
+func (g *AnalogGraph) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Root  int           `json:"root"`
+		Nodes []*AnalogNode `json:"nodes"`
+		Edges []AnalogEdge  `json:"edges"`
+	}{
+		Root:  g.Root,
+		Nodes: g.sortedNodes(),
+		Edges: g.Edges(),
+	})
+}
+
+// WriteDOT выводит граф в формате Graphviz DOT.
+func (g *AnalogGraph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("graph analogs {\n")
+	for _, n := range g.sortedNodes() {
+		attrs := ""
+		if n.ProductID == g.Root {
+			attrs = ", style=bold"
+		}
+		fmt.Fprintf(&sb, "  p%d [label=%q%s];\n", n.ProductID, n.Brand+"\n"+n.Code, attrs)
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(&sb, "  p%d -- p%d;\n", e.From, e.To)
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func sortNodes(nodes []*AnalogNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Brand != nodes[j].Brand {
+			return nodes[i].Brand < nodes[j].Brand
+		}
+		return nodes[i].Code < nodes[j].Code
+	})
+}
+
+// AnalogCrawler обходит аналоги через GetProductInfo в ширину до MaxDepth.
+// API отдает у аналогов только название бренда, поэтому для перехода к их аналогам
+// нужен BrandRegistry; аналоги с неизвестным брендом попадают в граф без раскрытия.
+type AnalogCrawler struct {
+	MaxDepth int
+	MaxNodes int
+
+	client *Client
+	brands *BrandRegistry
+}
+
+func NewAnalogCrawler(client *Client, brands *BrandRegistry) *AnalogCrawler {
+	return &AnalogCrawler{
+		MaxDepth: ANALOG_MAX_DEPTH,
+		MaxNodes: ANALOG_MAX_NODES,
+		client:   client,
+		brands:   brands,
+	}
+}
+
+// Crawl строит граф аналогов от детали brandID/code.
+func (ac *AnalogCrawler) Crawl(ctx context.Context, brandID int, code string) (*AnalogGraph, error) {
+	g := newAnalogGraph()
+	type item struct {
+		brandID int
+		code    string
+		depth   int
+	}
+	queue := []item{{brandID: brandID, code: code}}
+	for len(queue) > 0 {
+		it := queue[0]
+		queue = queue[1:]
+		info, err := ac.client.GetProductInfoCtx(ctx, it.code, it.brandID)
+		if err != nil {
+			if it.depth > 0 && errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return g, err
+		}
+		d := info.Data
+		node := g.Nodes[d.ProductID]
+		if node == nil {
+			node = &AnalogNode{
+				ProductID:      d.ProductID,
+				BrandID:        it.brandID,
+				Brand:          d.Brand,
+				Code:           d.Code,
+				DescriptionRus: d.DescriptionRus,
+				DescriptionUa:  d.DescriptionUa,
+				Weight:         d.Weight.Float64,
+				Volume:         d.Volume.Float64,
+				Depth:          it.depth,
+			}
+			g.Nodes[d.ProductID] = node
+		}
+		if it.depth == 0 {
+			g.Root = d.ProductID
+		}
+		node.Expanded = true
+		for _, a := range d.Analogs {
+			if a.ProductID == 0 {
+				continue
+			}
+			if _, ok := g.Nodes[a.ProductID]; ok {
+				g.addEdge(d.ProductID, a.ProductID)
+				continue
+			}
+			if ac.MaxNodes > 0 && len(g.Nodes) >= ac.MaxNodes {
+				continue
+			}
+			an := &AnalogNode{
+				ProductID:      a.ProductID,
+				Brand:          a.Brand,
+				Code:           a.Code,
+				DescriptionRus: a.DescriptionRus,
+				DescriptionUa:  a.DescriptionUa,
+				Weight:         a.Weight.Float64,
+				Volume:         a.Volume.Float64,
+				Depth:          it.depth + 1,
+			}
+			if ac.brands != nil {
+				if b, ok := ac.brands.ByName(a.Brand); ok {
+					an.BrandID = b.BrandID
+				}
+			}
+			g.Nodes[a.ProductID] = an
+			g.addEdge(d.ProductID, a.ProductID)
+			if an.BrandID != 0 && an.Depth < ac.MaxDepth {
+				queue = append(queue, item{brandID: an.BrandID, code: an.Code, depth: an.Depth})
+			}
+		}
+	}
+	return g, nil
+}
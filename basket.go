@@ -0,0 +1,215 @@
+package tehnomir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NuclearLouse/tehnomir/partnum"
+)
+
+// Total сумма строки корзины.
+func (p BasketPosition) Total() float64 {
+	return p.Price.Float64 * float64(p.Quantity)
+}
+
+// HasReference сравнивает reference без учета регистра, API отдает его в верхнем регистре.
+func (p BasketPosition) HasReference(reference string) bool {
+	return strings.EqualFold(p.Reference, reference)
+}
+
+// FindByReference ищет строку корзины по reference.
+func (r *BasketPositionsResponse) FindByReference(reference string) (BasketPosition, bool) {
+	for _, p := range r.Positions {
+		if p.HasReference(reference) {
+			return p, true
+		}
+	}
+	return BasketPosition{}, false
+}
+
+type BasketActionKind string
+
+const (
+	BasketActionAdd    BasketActionKind = "add"
+	BasketActionDelete BasketActionKind = "delete"
+	BasketActionUpdate BasketActionKind = "update" // добавление новой строки и удаление старой
+)
+
+var (
+	ErrEmptyReference     = errors.New("empty reference")
+	ErrDuplicateReference = errors.New("duplicate reference")
+	ErrUnmanagedReference = errors.New("reference outside managed prefix")
+)
+
+// BasketLine желаемая строка корзины, Reference обязателен и уникален.
+// Строка корзины не содержит productId, поэтому смена товара под тем же reference
+// видна только по BrandID и Code: если они заданы, строка с другим брендом или номером
+// заменяется. Без них смена ProductID требует нового reference.
+type BasketLine struct {
+	ProductID int64
+	PriceLogo string
+	Quantity  int
+	Reference string
+	Comment   string
+	BrandID   int    // необязательно, бренд товара ProductID
+	Code      string // необязательно, номер товара ProductID
+}
+
+// changed сравнивает строку корзины с желаемой по всем полям, которые отдает API.
+func (l BasketLine) changed(p BasketPosition) bool {
+	switch {
+	case p.Quantity != l.Quantity,
+		!strings.EqualFold(p.PriceLogo, l.PriceLogo),
+		!strings.EqualFold(strings.TrimSpace(p.Comment), strings.TrimSpace(l.Comment)),
+		l.BrandID != 0 && p.BrandID != l.BrandID,
+		l.Code != "" && !partnum.Equal(p.Brand, p.Code, l.Code):
+		return true
+	}
+	return false
+}
+
+type BasketAction struct {
+	Kind         BasketActionKind
+	Reference    string
+	BasketID     int // удаляемая строка
+	NewBasketID  int // добавленная строка
+	FromQuantity int
+	ToQuantity   int
+	// OldKept при обновлении новая строка добавлена, но старую удалить не удалось,
+	// в корзине обе строки с этим reference.
+	OldKept bool
+	Err     error
+}
+
+type BasketDiff struct {
+	Actions   []BasketAction
+	Unchanged int
+}
+
+// Failed действия, завершившиеся ошибкой.
+func (d *BasketDiff) Failed() []BasketAction {
+	var failed []BasketAction
+	for _, a := range d.Actions {
+		if a.Err != nil {
+			failed = append(failed, a)
+		}
+	}
+	return failed
+}
+
+// BasketManager приводит корзину к желаемому состоянию по reference: добавляет
+// недостающие строки, удаляет лишние, меняет количество, поставщика, комментарий и товар.
+type BasketManager struct {
+	// ReferencePrefix ограничивает управляемые строки корзины теми, чей reference
+	// начинается с префикса, остальные строки не трогаются. Пусто - вся корзина.
+	ReferencePrefix string
+
+	client *Client
+}
+
+func NewBasketManager(client *Client) *BasketManager {
+	return &BasketManager{
+		client: client,
+	}
+}
+
+// Plan возвращает действия, необходимые для приведения корзины к desired, не выполняя их.
+func (m *BasketManager) Plan(ctx context.Context, desired []BasketLine) (*BasketDiff, error) {
+	want := make(map[string]BasketLine, len(desired))
+	for _, l := range desired {
+		key := strings.ToUpper(l.Reference)
+		if key == "" {
+			return nil, fmt.Errorf("%w: product %d", ErrEmptyReference, l.ProductID)
+		}
+		if !m.managed(key) {
+			return nil, fmt.Errorf("%w: %s", ErrUnmanagedReference, l.Reference)
+		}
+		if _, ok := want[key]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateReference, l.Reference)
+		}
+		want[key] = l
+	}
+	basket, err := m.client.GetBasketPositionsCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	diff := &BasketDiff{}
+	have := make(map[string]bool)
+	for _, p := range basket.Positions {
+		key := strings.ToUpper(p.Reference)
+		if !m.managed(key) {
+			continue
+		}
+		l, ok := want[key]
+		switch {
+		case !ok || have[key]:
+			diff.Actions = append(diff.Actions, BasketAction{
+				Kind:         BasketActionDelete,
+				Reference:    p.Reference,
+				BasketID:     p.BasketID,
+				FromQuantity: p.Quantity,
+			})
+			continue
+		case l.changed(p):
+			diff.Actions = append(diff.Actions, BasketAction{
+				Kind:         BasketActionUpdate,
+				Reference:    l.Reference,
+				BasketID:     p.BasketID,
+				FromQuantity: p.Quantity,
+				ToQuantity:   l.Quantity,
+			})
+		default:
+			diff.Unchanged++
+		}
+		have[key] = true
+	}
+	for _, l := range desired {
+		if !have[strings.ToUpper(l.Reference)] {
+			diff.Actions = append(diff.Actions, BasketAction{
+				Kind:       BasketActionAdd,
+				Reference:  l.Reference,
+				ToQuantity: l.Quantity,
+			})
+		}
+	}
+	return diff, nil
+}
+
+// Sync приводит корзину к desired. Ошибки отдельных действий записываются в BasketAction.Err,
+// выполнение продолжается; ошибка возвращается, только если не удалось получить корзину.
+func (m *BasketManager) Sync(ctx context.Context, desired []BasketLine) (*BasketDiff, error) {
+	diff, err := m.Plan(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+	want := make(map[string]BasketLine, len(desired))
+	for _, l := range desired {
+		want[strings.ToUpper(l.Reference)] = l
+	}
+	for i := range diff.Actions {
+		a := &diff.Actions[i]
+		// при обновлении сначала добавляется новая строка, чтобы ошибка добавления
+		// не привела к потере старой
+		if a.Kind == BasketActionAdd || a.Kind == BasketActionUpdate {
+			l := want[strings.ToUpper(a.Reference)]
+			res, err := m.client.BasketAddCtx(ctx, l.ProductID, l.PriceLogo, l.Quantity, l.Reference, l.Comment)
+			if err != nil {
+				a.Err = err
+				continue
+			}
+			a.NewBasketID = res.Data.BasketID
+		}
+		if a.Kind == BasketActionDelete || a.Kind == BasketActionUpdate {
+			if a.Err = m.client.BasketDeletePositionCtx(ctx, a.BasketID); a.Err != nil {
+				a.OldKept = a.Kind == BasketActionUpdate
+			}
+		}
+	}
+	return diff, nil
+}
+
+func (m *BasketManager) managed(reference string) bool {
+	return m.ReferencePrefix == "" || strings.HasPrefix(reference, strings.ToUpper(m.ReferencePrefix))
+}
@@ -0,0 +1,278 @@
+package tehnomir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const BRAND_MIN_CONFIDENCE = 0.75
+
+var ErrBrandNotFound = errors.New("brand not found")
+
+type MatchMethod string
+
+const (
+	MatchExact MatchMethod = "exact"
+	MatchAlias MatchMethod = "alias"
+	MatchFuzzy MatchMethod = "fuzzy"
+)
+
+// AliasDictionary редактируемый словарь синонимов брендов: название бренда Техномира
+// и список вариантов написания, например "MERCEDES-BENZ": ["Mercedes", "MB"].
+// Хранится в JSON файле. Сам словарь синонимы не ищет: после Bind он заносит их
+// в BrandRegistry через AddAlias, и поиск идет только по синонимам справочника.
+type AliasDictionary struct {
+	mu       sync.RWMutex
+	brands   map[string][]string
+	registry *BrandRegistry
+}
+
+func NewAliasDictionary() *AliasDictionary {
+	return &AliasDictionary{
+		brands: make(map[string][]string),
+	}
+}
+
+func LoadAliasDictionary(path string) (*AliasDictionary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadAliasDictionary(f)
+}
+
+func ReadAliasDictionary(r io.Reader) (*AliasDictionary, error) {
+	var brands map[string][]string
+	if err := json.NewDecoder(r).Decode(&brands); err != nil {
+		return nil, fmt.Errorf("alias dictionary: %w", err)
+	}
+	d := NewAliasDictionary()
+	for brand, aliases := range brands {
+		d.Add(brand, aliases...)
+	}
+	return d, nil
+}
+
+func (d *AliasDictionary) Save(path string) error {
+	d.mu.RLock()
+	data, err := json.MarshalIndent(d.brands, "", "  ")
+	d.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Bind заносит синонимы в справочник и дальше передает ему изменения словаря,
+// после каждого BrandRegistry.Refresh синонимы заносятся заново.
+// Возвращает бренды словаря, которых нет в справочнике.
+func (d *AliasDictionary) Bind(r *BrandRegistry) []string {
+	d.mu.Lock()
+	d.registry = r
+	d.mu.Unlock()
+	r.mu.Lock()
+	r.dict = d
+	r.mu.Unlock()
+	return d.apply(r)
+}
+
+func (d *AliasDictionary) apply(r *BrandRegistry) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var unknown []string
+	for brand, aliases := range d.brands {
+		b, ok := r.ByName(brand)
+		if !ok {
+			unknown = append(unknown, brand)
+			continue
+		}
+		for _, a := range aliases {
+			r.AddAlias(a, b.BrandID)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// Add добавляет синонимы к бренду.
+func (d *AliasDictionary) Add(brand string, aliases ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var target Brand
+	bound := false
+	if d.registry != nil {
+		target, bound = d.registry.ByName(brand)
+	}
+	for _, a := range aliases {
+		key := NormalizeBrandName(a)
+		if key == "" {
+			continue
+		}
+		d.remove(key)
+		d.brands[brand] = append(d.brands[brand], a)
+		if bound {
+			d.registry.AddAlias(a, target.BrandID)
+		}
+	}
+}
+
+// Remove удаляет синоним.
+func (d *AliasDictionary) Remove(alias string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.remove(NormalizeBrandName(alias)) && d.registry != nil {
+		d.registry.RemoveAlias(alias)
+	}
+}
+
+func (d *AliasDictionary) remove(key string) bool {
+	found := false
+	for brand, aliases := range d.brands {
+		out := aliases[:0]
+		for _, a := range aliases {
+			if NormalizeBrandName(a) == key {
+				found = true
+				continue
+			}
+			out = append(out, a)
+		}
+		if len(out) == 0 {
+			delete(d.brands, brand)
+		} else {
+			d.brands[brand] = out
+		}
+	}
+	return found
+}
+
+type BrandMatch struct {
+	Brand      Brand
+	Confidence float64 // 0..1
+	Method     MatchMethod
+}
+
+// BrandMatcher сопоставляет произвольное написание бренда с BrandID Техномира:
+// точное совпадение, словарь синонимов, затем нечеткое сравнение.
+type BrandMatcher struct {
+	MinConfidence float64
+
+	registry *BrandRegistry
+	aliases  *AliasDictionary
+}
+
+// NewBrandMatcher создает сопоставитель и привязывает aliases к registry, aliases может быть nil.
+func NewBrandMatcher(registry *BrandRegistry, aliases *AliasDictionary) *BrandMatcher {
+	if aliases == nil {
+		aliases = NewAliasDictionary()
+	}
+	aliases.Bind(registry)
+	return &BrandMatcher{
+		MinConfidence: BRAND_MIN_CONFIDENCE,
+		registry:      registry,
+		aliases:       aliases,
+	}
+}
+
+func (m *BrandMatcher) Aliases() *AliasDictionary {
+	return m.aliases
+}
+
+// Match возвращает лучший вариант с уверенностью не ниже MinConfidence.
+func (m *BrandMatcher) Match(input string) (BrandMatch, error) {
+	candidates := m.Candidates(input, 1)
+	if len(candidates) == 0 || candidates[0].Confidence < m.MinConfidence {
+		return BrandMatch{}, fmt.Errorf("%w: %q", ErrBrandNotFound, input)
+	}
+	return candidates[0], nil
+}
+
+// Candidates возвращает до n вариантов, отсортированных по убыванию уверенности.
+func (m *BrandMatcher) Candidates(input string, n int) []BrandMatch {
+	key := NormalizeBrandName(input)
+	if key == "" {
+		return nil
+	}
+	if b, ok := m.registry.ByName(input); ok {
+		method := MatchExact
+		if NormalizeBrandName(b.BrandName) != key {
+			method = MatchAlias
+		}
+		return []BrandMatch{{Brand: b, Confidence: 1, Method: method}}
+	}
+	var matches []BrandMatch
+	for _, b := range m.registry.Brands() {
+		score := brandSimilarity(key, NormalizeBrandName(b.BrandName))
+		if score > 0 {
+			matches = append(matches, BrandMatch{Brand: b, Confidence: score, Method: MatchFuzzy})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence > matches[j].Confidence
+	})
+	if n > 0 && len(matches) > n {
+		matches = matches[:n]
+	}
+	return matches
+}
+
+// brandSimilarity сходство нормализованных названий: 1 - расстояние Левенштейна
+// к длине большей строки, совпадение по началу (MERCEDES - MERCEDESBENZ) повышает оценку.
+func brandSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	long, short := len(ra), len(rb)
+	if short > long {
+		long, short = short, long
+	}
+	if long == 0 {
+		return 0
+	}
+	score := 1 - float64(levenshtein(ra, rb))/float64(long)
+	if short >= 3 && (strings.HasPrefix(a, b) || strings.HasPrefix(b, a)) {
+		if prefix := 0.8 + 0.2*float64(short)/float64(long); prefix > score {
+			score = prefix
+		}
+	}
+	return score
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// SearchByBrandName поиск по номеру и бренду в свободном написании.
+func (c *Client) SearchByBrandName(ctx context.Context, m *BrandMatcher, code, brand string, analogs bool, currency ...Currency) (*PriceSearchResponse, BrandMatch, error) {
+	match, err := m.Match(brand)
+	if err != nil {
+		return nil, BrandMatch{}, err
+	}
+	cur := USD
+	if currency != nil {
+		cur = currency[0]
+	}
+	res, err := c.priceSearch(ctx, code, cur, match.Brand.BrandID, analogs)
+	return res, match, err
+}
@@ -0,0 +1,229 @@
+package tehnomir
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// BrandRegistry справочник брендов и групп брендов с поиском по ID, названию и синонимам.
+// Безопасен для использования из нескольких горутин.
+type BrandRegistry struct {
+	// OnError вызывается при ошибке фонового обновления.
+	OnError func(error)
+
+	client *Client
+
+	mu      sync.RWMutex
+	byID    map[int]Brand
+	byName  map[string]int
+	aliases map[string]int
+	dict    *AliasDictionary
+	groups  map[int]BrandGroup
+	groupOf map[int][]int
+	loaded  time.Time
+}
+
+func NewBrandRegistry(client *Client) *BrandRegistry {
+	return &BrandRegistry{
+		client:  client,
+		byID:    make(map[int]Brand),
+		byName:  make(map[string]int),
+		aliases: make(map[string]int),
+		groups:  make(map[int]BrandGroup),
+		groupOf: make(map[int][]int),
+	}
+}
+
+// Refresh загружает бренды и группы брендов из API, минуя кэш клиента.
+func (r *BrandRegistry) Refresh(ctx context.Context) error {
+	ctx = WithoutCache(ctx)
+	brands, err := r.client.GetBrandsCtx(ctx)
+	if err != nil {
+		return err
+	}
+	groups, err := r.client.GetBrandGroupsCtx(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int]Brand, len(brands.Brands))
+	byName := make(map[string]int, len(brands.Brands))
+	for _, b := range brands.Brands {
+		byID[b.BrandID] = b
+		byName[NormalizeBrandName(b.BrandName)] = b.BrandID
+	}
+	byGroup := make(map[int]BrandGroup, len(groups.BrandGroups))
+	groupOf := make(map[int][]int)
+	for _, g := range groups.BrandGroups {
+		byGroup[g.GroupID] = g
+		for _, id := range g.BrandIds {
+			groupOf[id.Int] = append(groupOf[id.Int], g.GroupID)
+		}
+	}
+	r.mu.Lock()
+	r.byID, r.byName, r.groups, r.groupOf = byID, byName, byGroup, groupOf
+	r.loaded = time.Now()
+	dict := r.dict
+	r.mu.Unlock()
+	if dict != nil {
+		if unknown := dict.apply(r); len(unknown) > 0 && r.OnError != nil {
+			r.OnError(fmt.Errorf("%w: alias dictionary: %s", ErrBrandNotFound, strings.Join(unknown, ", ")))
+		}
+	}
+	return nil
+}
+
+// StartRefresh обновляет справочник каждые interval в фоне до отмены ctx.
+// Первое обновление выполняется сразу.
+func (r *BrandRegistry) StartRefresh(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := r.Refresh(ctx); err != nil && ctx.Err() == nil && r.OnError != nil {
+				r.OnError(err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// LoadedAt время последнего успешного обновления.
+func (r *BrandRegistry) LoadedAt() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loaded
+}
+
+// AddAlias добавляет синоним названия бренда, синонимы сохраняются при обновлении.
+func (r *BrandRegistry) AddAlias(alias string, brandID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aliases[NormalizeBrandName(alias)] = brandID
+}
+
+func (r *BrandRegistry) RemoveAlias(alias string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.aliases, NormalizeBrandName(alias))
+}
+
+func (r *BrandRegistry) ByID(id int) (Brand, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	b, ok := r.byID[id]
+	return b, ok
+}
+
+// ByName ищет бренд по названию без учета регистра и знаков препинания, затем по синонимам.
+func (r *BrandRegistry) ByName(name string) (Brand, bool) {
+	key := NormalizeBrandName(name)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.byName[key]
+	if !ok {
+		id, ok = r.aliases[key]
+	}
+	if !ok {
+		return Brand{}, false
+	}
+	b, ok := r.byID[id]
+	return b, ok
+}
+
+// Brands все бренды, отсортированные по названию.
+func (r *BrandRegistry) Brands() []Brand {
+	r.mu.RLock()
+	brands := make([]Brand, 0, len(r.byID))
+	for _, b := range r.byID {
+		brands = append(brands, b)
+	}
+	r.mu.RUnlock()
+	sort.Slice(brands, func(i, j int) bool {
+		return brands[i].BrandName < brands[j].BrandName
+	})
+	return brands
+}
+
+func (r *BrandRegistry) Group(groupID int) (BrandGroup, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	g, ok := r.groups[groupID]
+	return g, ok
+}
+
+// GroupsOf группы, в которые входит бренд.
+func (r *BrandRegistry) GroupsOf(brandID int) []BrandGroup {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var groups []BrandGroup
+	for _, id := range r.groupOf[brandID] {
+		groups = append(groups, r.groups[id])
+	}
+	return groups
+}
+
+// BrandsInGroup бренды группы, неизвестные справочнику ID пропускаются.
+func (r *BrandRegistry) BrandsInGroup(groupID int) []Brand {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var brands []Brand
+	for _, id := range r.groups[groupID].BrandIds {
+		if b, ok := r.byID[id.Int]; ok {
+			brands = append(brands, b)
+		}
+	}
+	return brands
+}
+
+// SameGroup true, если бренды входят в одну группу.
+func (r *BrandRegistry) SameGroup(a, b int) bool {
+	if a == b {
+		return true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, ga := range r.groupOf[a] {
+		for _, gb := range r.groupOf[b] {
+			if ga == gb {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NormalizeBrandName приводит название бренда к верхнему регистру и оставляет только буквы и цифры.
+func NormalizeBrandName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// HasOffers true, если по номеру у бренда есть предложения.
+func (b BrandByCode) HasOffers() bool {
+	return b.OffersCount > 0
+}
+
+// WithOffers бренды, у которых есть предложения.
+func (r *BrandsByCodeResponse) WithOffers() []BrandByCode {
+	var brands []BrandByCode
+	for _, b := range r.Data {
+		if b.HasOffers() {
+			brands = append(brands, b)
+		}
+	}
+	return brands
+}
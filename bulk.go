@@ -0,0 +1,101 @@
+package tehnomir
+
+import (
+	"context"
+	"sync"
+)
+
+const SEARCH_CONCURRENCY = 8
+
+// SearchQuery один запрос в пакетном поиске. BrandID 0 - поиск без бренда,
+// пустой Currency - USD.
+type SearchQuery struct {
+	Code     string
+	BrandID  int
+	Analogs  bool
+	Currency Currency
+}
+
+type SearchResult struct {
+	Index    int // индекс запроса во входном списке
+	Query    SearchQuery
+	Response *PriceSearchResponse
+	Err      error
+}
+
+// SearchMany выполняет поиск по списку запросов параллельно, не более concurrency
+// одновременно (0 - SEARCH_CONCURRENCY). Результаты в порядке входного списка,
+// при отмене ctx необработанные запросы получают ошибку ctx.Err().
+// Лимиты RateLimiter клиента соблюдаются.
+func (c *Client) SearchMany(ctx context.Context, queries []SearchQuery, concurrency int) []SearchResult {
+	results := make([]SearchResult, len(queries))
+	done := make([]bool, len(queries))
+	for res := range c.SearchManyStream(ctx, queries, concurrency) {
+		results[res.Index] = res
+		done[res.Index] = true
+	}
+	for i, ok := range done {
+		if !ok {
+			results[i] = SearchResult{Index: i, Query: queries[i], Err: ctx.Err()}
+		}
+	}
+	return results
+}
+
+// SearchManyStream как SearchMany, но отдает результаты в канал по мере готовности.
+// Канал закрывается, когда обработаны все запросы или отменен ctx. После отмены
+// результаты по оставшимся запросам в канал не приходят, поэтому потребитель может
+// просто перестать читать канал, отменив ctx.
+func (c *Client) SearchManyStream(ctx context.Context, queries []SearchQuery, concurrency int) <-chan SearchResult {
+	if concurrency <= 0 {
+		concurrency = SEARCH_CONCURRENCY
+	}
+	out := make(chan SearchResult, concurrency)
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(queries); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				select {
+				case out <- c.searchOne(ctx, idx, queries[idx]):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+	feed:
+		for idx := range queries {
+			select {
+			case jobs <- idx:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(jobs)
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+func (c *Client) searchOne(ctx context.Context, idx int, q SearchQuery) SearchResult {
+	res := SearchResult{
+		Index: idx,
+		Query: q,
+	}
+	if err := ctx.Err(); err != nil {
+		res.Err = err
+		return res
+	}
+	cur := q.Currency
+	if cur == "" {
+		cur = USD
+	}
+	res.Response, res.Err = c.priceSearch(ctx, q.Code, cur, q.BrandID, q.Analogs)
+	return res
+}
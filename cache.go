@@ -0,0 +1,295 @@
+package tehnomir
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	CACHE_TTL       = time.Hour
+	CACHE_MAX_STALE = 24 * time.Hour
+	CACHE_CAPACITY  = 64
+)
+
+// CacheEntry сырое тело ответа API и время его получения.
+type CacheEntry struct {
+	Data     []byte
+	StoredAt time.Time
+}
+
+// Cache хранилище ответов справочных методов API (бренды, группы брендов, поставщики,
+// курсы валют, статусы позиций). Get возвращает nil, nil при отсутствии ключа.
+// Реализация должна быть безопасна для использования из нескольких горутин.
+type Cache interface {
+	Get(ctx context.Context, key string) (*CacheEntry, error)
+	Set(ctx context.Context, key string, entry *CacheEntry) error
+	Delete(ctx context.Context, key string) error
+}
+
+// CacheConfig настройки кэширования справочников.
+type CacheConfig struct {
+	TTL time.Duration
+	// StaleWhileRevalidate отдает устаревшие данные (не старше TTL+MaxStale)
+	// и обновляет их в фоне.
+	StaleWhileRevalidate bool
+	MaxStale             time.Duration
+}
+
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		TTL:      CACHE_TTL,
+		MaxStale: CACHE_MAX_STALE,
+	}
+}
+
+var cachedPaths = []ApiPath{
+	GetBrands,
+	GetBrandGroups,
+	GetSuppliers,
+	GetCurrencies,
+	GetPositionStatuses,
+}
+
+type referenceCache struct {
+	store    Cache
+	cfg      CacheConfig
+	inflight sync.Map
+}
+
+type cacheBypassKey struct{}
+
+// WithoutCache возвращает контекст, в котором справочные методы идут в API мимо кэша
+// и обновляют сохраненный ответ.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+func bypassCache(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// cacheKey ключ кэша включает хост и хэш токена, чтобы общее хранилище
+// не отдавало данные одного аккаунта другому.
+func (c *Client) cacheKey(request ApiPath) string {
+	sum := sha256.Sum256([]byte(c.cfg.Token))
+	return c.cfg.Host + "/" + hex.EncodeToString(sum[:8]) + "/" + string(request)
+}
+
+// cachedRequestAndDecode запрос без тела с кэшированием ответа.
+func (c *Client) cachedRequestAndDecode(ctx context.Context, request ApiPath, response any) error {
+	rc := c.cache
+	if rc == nil || rc.store == nil {
+		return c.requestAndDecode(ctx, request, response)
+	}
+	if !bypassCache(ctx) {
+		entry, err := rc.store.Get(ctx, c.cacheKey(request))
+		if err == nil && entry != nil {
+			age := time.Since(entry.StoredAt)
+			switch {
+			case age <= rc.cfg.TTL:
+				return json.Unmarshal(entry.Data, response)
+			case rc.cfg.StaleWhileRevalidate && age <= rc.cfg.TTL+rc.cfg.MaxStale:
+				c.revalidate(request)
+				return json.Unmarshal(entry.Data, response)
+			}
+		}
+	}
+	data, err := c.fetchAndStore(ctx, request)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, response)
+}
+
+func (c *Client) fetchAndStore(ctx context.Context, request ApiPath) ([]byte, error) {
+	var raw json.RawMessage
+	if err := c.requestAndDecode(ctx, request, &raw); err != nil {
+		return nil, err
+	}
+	// ошибка записи в кэш не должна ломать запрос
+	_ = c.cache.store.Set(ctx, c.cacheKey(request), &CacheEntry{
+		Data:     raw,
+		StoredAt: time.Now(),
+	})
+	return raw, nil
+}
+
+func (c *Client) revalidate(request ApiPath) {
+	if _, busy := c.cache.inflight.LoadOrStore(request, struct{}{}); busy {
+		return
+	}
+	go func() {
+		defer c.cache.inflight.Delete(request)
+		ctx, cancel := context.WithTimeout(context.Background(), durationOrDefault(c.cfg.Timeout, TIMEOUT))
+		defer cancel()
+		_, _ = c.fetchAndStore(ctx, request)
+	}()
+}
+
+// InvalidateCache удаляет из кэша ответы указанных методов, без аргументов - все справочники.
+func (c *Client) InvalidateCache(ctx context.Context, paths ...ApiPath) error {
+	if c.cache == nil || c.cache.store == nil {
+		return nil
+	}
+	if paths == nil {
+		paths = cachedPaths
+	}
+	var errs []error
+	for _, p := range paths {
+		if err := c.cache.store.Delete(ctx, c.cacheKey(p)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// MemoryCache LRU кэш в памяти. Записи старше maxAge удаляются при чтении.
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	maxAge   time.Duration
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type memoryItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewMemoryCache создает LRU кэш на capacity записей, maxAge 0 - без ограничения возраста.
+func NewMemoryCache(capacity int, maxAge time.Duration) *MemoryCache {
+	if capacity <= 0 {
+		capacity = CACHE_CAPACITY
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		maxAge:   maxAge,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (m *MemoryCache) Get(_ context.Context, key string) (*CacheEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.items[key]
+	if !ok {
+		return nil, nil
+	}
+	item := el.Value.(*memoryItem)
+	if m.maxAge > 0 && time.Since(item.entry.StoredAt) > m.maxAge {
+		m.order.Remove(el)
+		delete(m.items, key)
+		return nil, nil
+	}
+	m.order.MoveToFront(el)
+	entry := item.entry
+	return &entry, nil
+}
+
+func (m *MemoryCache) Set(_ context.Context, key string, entry *CacheEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.items[key]; ok {
+		el.Value.(*memoryItem).entry = *entry
+		m.order.MoveToFront(el)
+		return nil
+	}
+	m.items[key] = m.order.PushFront(&memoryItem{key: key, entry: *entry})
+	for m.order.Len() > m.capacity {
+		last := m.order.Back()
+		m.order.Remove(last)
+		delete(m.items, last.Value.(*memoryItem).key)
+	}
+	return nil
+}
+
+func (m *MemoryCache) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.items[key]; ok {
+		m.order.Remove(el)
+		delete(m.items, key)
+	}
+	return nil
+}
+
+// FileCache хранит каждую запись в отдельном файле в каталоге dir,
+// переживает перезапуск приложения.
+type FileCache struct {
+	dir string
+	mu  sync.Mutex
+}
+
+type fileEntry struct {
+	Data     json.RawMessage `json:"data"`
+	StoredAt time.Time       `json:"storedAt"`
+}
+
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+func (f *FileCache) path(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, key)
+	return filepath.Join(f.dir, name+".json")
+}
+
+func (f *FileCache) Get(_ context.Context, key string) (*CacheEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, err := os.ReadFile(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var fe fileEntry
+	if err := json.Unmarshal(data, &fe); err != nil {
+		return nil, err
+	}
+	return &CacheEntry{Data: fe.Data, StoredAt: fe.StoredAt}, nil
+}
+
+func (f *FileCache) Set(_ context.Context, key string, entry *CacheEntry) error {
+	data, err := json.Marshal(fileEntry{Data: entry.Data, StoredAt: entry.StoredAt})
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tmp := f.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path(key))
+}
+
+func (f *FileCache) Delete(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.Remove(f.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
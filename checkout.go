@@ -0,0 +1,176 @@
+package tehnomir
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+const CHECKOUT_MAX_PRICE_INCREASE = 0.05
+
+var (
+	ErrBasketEmpty     = errors.New("basket is empty")
+	ErrCheckoutAborted = errors.New("checkout aborted")
+)
+
+type CheckoutIssueKind string
+
+const (
+	IssuePriceIncrease CheckoutIssueKind = "price_increase"
+	IssueOfferVanished CheckoutIssueKind = "offer_vanished"
+	IssueQuantity      CheckoutIssueKind = "quantity"
+	IssueSearchFailed  CheckoutIssueKind = "search_failed"
+)
+
+type CheckoutIssue struct {
+	Kind    CheckoutIssueKind `json:"kind"`
+	Message string            `json:"message"`
+}
+
+type CheckoutOptions struct {
+	// MaxPriceIncrease допустимый рост цены в долях (0.05 - 5%).
+	MaxPriceIncrease float64
+	// AbortOnIssues отменяет создание заказа при любой проблеме в строках корзины.
+	AbortOnIssues bool
+}
+
+func DefaultCheckoutOptions() CheckoutOptions {
+	return CheckoutOptions{
+		MaxPriceIncrease: CHECKOUT_MAX_PRICE_INCREASE,
+		AbortOnIssues:    true,
+	}
+}
+
+type CheckoutLine struct {
+	Position     BasketPosition  `json:"position"`
+	Offer        *OfferSupplier  `json:"offer,omitempty"` // актуальное предложение
+	CurrentPrice float64         `json:"currentPrice"`
+	PriceChange  float64         `json:"priceChange"` // в долях от цены в корзине
+	Issues       []CheckoutIssue `json:"issues,omitempty"`
+}
+
+// CheckoutReport полный протокол оформления заказа.
+type CheckoutReport struct {
+	OrderNumber string         `json:"orderNumber"`
+	StartedAt   time.Time      `json:"startedAt"`
+	FinishedAt  time.Time      `json:"finishedAt"`
+	Lines       []CheckoutLine `json:"lines"`
+	Aborted     bool           `json:"aborted"`
+	Order       *Order         `json:"order,omitempty"`
+	Positions   []Position     `json:"positions,omitempty"`
+	Err         string         `json:"error,omitempty"`
+}
+
+// HasIssues true, если хотя бы у одной строки есть проблема.
+func (r *CheckoutReport) HasIssues() bool {
+	for _, l := range r.Lines {
+		if len(l.Issues) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Checkout переоценивает все строки корзины, проверяет рост цен и наличие предложений,
+// создает заказ ordernum и возвращает его вместе с позициями. Отчет возвращается
+// и при ошибке, в том числе при ErrCheckoutAborted.
+func (c *Client) Checkout(ctx context.Context, ordernum string, opts CheckoutOptions) (*CheckoutReport, error) {
+	report := &CheckoutReport{
+		OrderNumber: ordernum,
+		StartedAt:   time.Now(),
+	}
+	err := c.checkout(ctx, report, opts)
+	report.FinishedAt = time.Now()
+	if err != nil {
+		report.Err = err.Error()
+	}
+	return report, err
+}
+
+func (c *Client) checkout(ctx context.Context, report *CheckoutReport, opts CheckoutOptions) error {
+	basket, err := c.GetBasketPositionsCtx(ctx)
+	if err != nil {
+		return err
+	}
+	if len(basket.Positions) == 0 {
+		return ErrBasketEmpty
+	}
+	queries := make([]SearchQuery, len(basket.Positions))
+	for i, p := range basket.Positions {
+		queries[i] = SearchQuery{
+			Code:     p.Code,
+			BrandID:  p.BrandID,
+			Currency: Currency(p.Currency),
+		}
+	}
+	for i, res := range c.SearchMany(ctx, queries, 0) {
+		report.Lines = append(report.Lines, repriceLine(basket.Positions[i], res, opts))
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if opts.AbortOnIssues && report.HasIssues() {
+		report.Aborted = true
+		return ErrCheckoutAborted
+	}
+	order, err := c.OrderCreateCtx(ctx, report.OrderNumber)
+	if err != nil {
+		return err
+	}
+	report.Order = &order.Order
+	positions, err := c.OrderPositionsCtx(ctx, order.Order.OrderID)
+	if err != nil {
+		return err
+	}
+	report.Positions = positions.Positions
+	return nil
+}
+
+func repriceLine(p BasketPosition, res SearchResult, opts CheckoutOptions) CheckoutLine {
+	line := CheckoutLine{Position: p}
+	if res.Err != nil {
+		line.Issues = append(line.Issues, CheckoutIssue{Kind: IssueSearchFailed, Message: res.Err.Error()})
+		return line
+	}
+	line.Offer = findOffer(res.Response, p)
+	if line.Offer == nil {
+		line.Issues = append(line.Issues, CheckoutIssue{
+			Kind:    IssueOfferVanished,
+			Message: "offer " + p.PriceLogo + " not found for " + p.Brand + " " + p.Code,
+		})
+		return line
+	}
+	line.CurrentPrice = line.Offer.Price.Float64
+	if old := p.Price.Float64; old > 0 {
+		line.PriceChange = (line.CurrentPrice - old) / old
+		if line.PriceChange > opts.MaxPriceIncrease {
+			line.Issues = append(line.Issues, CheckoutIssue{
+				Kind:    IssuePriceIncrease,
+				Message: p.Price.Decimal().String() + " -> " + line.Offer.Price.Decimal().String(),
+			})
+		}
+	}
+	if err := ValidateQuantity(line.Offer, p.Quantity); err != nil {
+		line.Issues = append(line.Issues, CheckoutIssue{Kind: IssueQuantity, Message: err.Error()})
+	}
+	return line
+}
+
+func findOffer(res *PriceSearchResponse, p BasketPosition) *OfferSupplier {
+	if res == nil {
+		return nil
+	}
+	for i := range res.Details {
+		d := &res.Details[i]
+		if d.BrandID != p.BrandID || normCode(d.Code) != normCode(p.Code) {
+			continue
+		}
+		for j := range d.Stocks {
+			if strings.EqualFold(d.Stocks[j].PriceLogo, p.PriceLogo) {
+				return &d.Stocks[j]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package tehnomir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NuclearLouse/tehnomir/utilits"
+)
+
+const CURRENCY_RATES_TTL = time.Hour
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Money сумма в валюте.
+type Money struct {
+	Amount   utilits.Decimal `json:"amount"`
+	Currency Currency        `json:"currency"`
+}
+
+func NewMoney(amount float64, currency string) Money {
+	return Money{
+		Amount:   utilits.DecimalFromFloat(amount),
+		Currency: Currency(strings.ToUpper(currency)),
+	}
+}
+
+func (m Money) String() string {
+	return m.Amount.StringFixed(2) + " " + string(m.Currency)
+}
+
+// CurrencyConverter пересчитывает суммы по курсам из GetCurrencies.
+// Курс валюты считается ценой ее единицы в базовой валюте (UAH), для базовой валюты
+// курс 1, даже если API ее не вернул. Курсы кэшируются на TTL.
+type CurrencyConverter struct {
+	Base Currency
+
+	client *Client
+	ttl    time.Duration
+
+	mu     sync.Mutex
+	rates  map[Currency]utilits.Decimal
+	loaded time.Time
+}
+
+func NewCurrencyConverter(client *Client, ttl time.Duration) *CurrencyConverter {
+	if ttl <= 0 {
+		ttl = CURRENCY_RATES_TTL
+	}
+	return &CurrencyConverter{
+		Base:   UAH,
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// Refresh загружает курсы из API независимо от TTL, минуя кэш клиента.
+func (cc *CurrencyConverter) Refresh(ctx context.Context) error {
+	res, err := cc.client.GetCurrenciesCtx(WithoutCache(ctx))
+	if err != nil {
+		return err
+	}
+	rates := make(map[Currency]utilits.Decimal, len(res.Currencies)+1)
+	rates[cc.Base] = utilits.DecimalFromInt(1)
+	for _, r := range res.Currencies {
+		rates[Currency(strings.ToUpper(r.Currency))] = r.Rate.Decimal()
+	}
+	cc.mu.Lock()
+	cc.rates = rates
+	cc.loaded = time.Now()
+	cc.mu.Unlock()
+	return nil
+}
+
+// Rates возвращает копию текущих курсов, при необходимости обновляя их.
+func (cc *CurrencyConverter) Rates(ctx context.Context) (map[Currency]utilits.Decimal, error) {
+	cc.mu.Lock()
+	expired := cc.rates == nil || time.Since(cc.loaded) > cc.ttl
+	cc.mu.Unlock()
+	if expired {
+		if err := cc.Refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	rates := make(map[Currency]utilits.Decimal, len(cc.rates))
+	for k, v := range cc.rates {
+		rates[k] = v
+	}
+	return rates, nil
+}
+
+// Convert пересчитывает amount из валюты from в валюту to.
+func (cc *CurrencyConverter) Convert(ctx context.Context, amount utilits.Decimal, from, to Currency) (utilits.Decimal, error) {
+	from, to = Currency(strings.ToUpper(string(from))), Currency(strings.ToUpper(string(to)))
+	if from == to {
+		return amount, nil
+	}
+	rates, err := cc.Rates(ctx)
+	if err != nil {
+		return utilits.Decimal{}, err
+	}
+	rateFrom, ok := rates[from]
+	if !ok || rateFrom.IsZero() {
+		return utilits.Decimal{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, from)
+	}
+	rateTo, ok := rates[to]
+	if !ok || rateTo.IsZero() {
+		return utilits.Decimal{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, to)
+	}
+	return amount.Mul(rateFrom).Div(rateTo), nil
+}
+
+func (cc *CurrencyConverter) ConvertMoney(ctx context.Context, m Money, to Currency) (Money, error) {
+	amount, err := cc.Convert(ctx, m.Amount, m.Currency, to)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: to}, nil
+}
+
+// ConvertOffer возвращает цену предложения в валюте to.
+func (cc *CurrencyConverter) ConvertOffer(ctx context.Context, o *OfferSupplier, to Currency) (Money, error) {
+	return cc.ConvertMoney(ctx, NewMoney(o.Price.Float64, o.Currency), to)
+}
+
+// ConvertPosition возвращает цену позиции заказа в валюте to.
+func (cc *CurrencyConverter) ConvertPosition(ctx context.Context, p *Position, to Currency) (Money, error) {
+	return cc.ConvertMoney(ctx, NewMoney(p.Price.Float64, p.Currency), to)
+}
+
+// ConvertUnloadPosition возвращает Price и PriceFinal позиции отгрузки в валюте to.
+func (cc *CurrencyConverter) ConvertUnloadPosition(ctx context.Context, p *UnloadPosition, to Currency) (price, priceFinal Money, err error) {
+	price, err = cc.ConvertMoney(ctx, NewMoney(p.Price.Float64, p.Currency), to)
+	if err != nil {
+		return
+	}
+	priceFinal, err = cc.ConvertMoney(ctx, NewMoney(p.PriceFinal.Float64, p.Currency), to)
+	return
+}
+
+func (r CurrencyRate) Code() Currency {
+	return Currency(strings.ToUpper(r.Currency))
+}
+
+// Rate ищет курс валюты в ответе.
+func (r *CurrenciesResponse) Rate(cur Currency) (CurrencyRate, bool) {
+	for _, c := range r.Currencies {
+		if c.Code() == Currency(strings.ToUpper(string(cur))) {
+			return c, true
+		}
+	}
+	return CurrencyRate{}, false
+}
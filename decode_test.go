@@ -0,0 +1,349 @@
+package tehnomir
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NuclearLouse/tehnomir/utilits"
+)
+
+// readGolden читает эталонный ответ API из testdata.
+// Файлы повторяют структуру ответов из документации API, значения подобраны
+// под граничные случаи разбора и не сняты с боевого API.
+func readGolden(t *testing.T, file string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		t.Fatalf("read golden %s: %v", file, err)
+	}
+	return data
+}
+
+// decodeGolden декодирует эталонный ответ так же, как doRequestAndDecode.
+func decodeGolden(t *testing.T, path ApiPath, file string, res any) {
+	t.Helper()
+	data := readGolden(t, file)
+	if err := checkSuccess(path, http.StatusOK, data); err != nil {
+		t.Fatalf("checkSuccess %s: %v", file, err)
+	}
+	if err := json.Unmarshal(data, res); err != nil {
+		t.Fatalf("unmarshal %s: %v", file, err)
+	}
+}
+
+func wantMoney(t *testing.T, field string, got utilits.CustomFloat64, want float64) {
+	t.Helper()
+	if got.Float64 != want {
+		t.Errorf("%s = %v, want %v", field, got.Float64, want)
+	}
+}
+
+func wantTime(t *testing.T, field string, got utilits.CustomTime, want string) {
+	t.Helper()
+	if want == "" {
+		if !time.Time(got).IsZero() {
+			t.Errorf("%s = %v, want zero time", field, time.Time(got))
+		}
+		return
+	}
+	w, err := time.Parse("2006-01-02 15:04:05", want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !time.Time(got).Equal(w) {
+		t.Errorf("%s = %v, want %v", field, time.Time(got), w)
+	}
+}
+
+func TestDecodeGolden(t *testing.T) {
+	tests := []struct {
+		path  ApiPath
+		file  string
+		check func(t *testing.T, path ApiPath, file string)
+	}{
+		{TestConnect, "test_connect.json", func(t *testing.T, path ApiPath, file string) {
+			var res TestConnectResponse
+			decodeGolden(t, path, file, &res)
+			if !res.Success || res.Data.TestString != "ping" {
+				t.Errorf("got %+v", res)
+			}
+		}},
+		{PriceSearch, "price_search.json", func(t *testing.T, path ApiPath, file string) {
+			var res PriceSearchResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Details) != 1 || len(res.Details[0].Stocks) != 2 {
+				t.Fatalf("got %+v", res.Details)
+			}
+			d := res.Details[0]
+			wantMoney(t, "weight", d.Weight, 0.35)
+			if bool(d.IsOriginal) || !bool(d.IsExistProductInfo) {
+				t.Errorf("flags = %v, %v", bool(d.IsOriginal), bool(d.IsExistProductInfo))
+			}
+			s0, s1 := d.Stocks[0], d.Stocks[1]
+			wantMoney(t, "rests[0].price", s0.Price, 5.37)
+			wantMoney(t, "rests[1].price", s1.Price, 1234.56)
+			if s0.Quantity.Int64 != 12 || s1.Quantity.Int64 != 0 {
+				t.Errorf("quantity = %d, %d", s0.Quantity.Int64, s1.Quantity.Int64)
+			}
+			wantTime(t, "rests[0].deliveryDate", s0.DeliveryDate, "2023-11-16 00:00:00")
+			wantTime(t, "rests[0].priceChangeDate", s0.PriceChangeDate, "")
+			wantTime(t, "rests[1].deliveryDate", s1.DeliveryDate, "")
+			wantTime(t, "rests[1].priceChangeDate", s1.PriceChangeDate, "2023-11-02 08:00:00")
+			if bool(s1.IsReturn) || !bool(s1.IsPriceFinal) {
+				t.Errorf("rests[1] flags = %v, %v", bool(s1.IsReturn), bool(s1.IsPriceFinal))
+			}
+		}},
+		{GetStockPrice, "price_get_stock_price.json", func(t *testing.T, path ApiPath, file string) {
+			var res StockPriceResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Products) != 1 {
+				t.Fatalf("got %+v", res.Products)
+			}
+			wantMoney(t, "price", res.Products[0].Price, 1234.50)
+			wantMoney(t, "priceForRemote", res.Products[0].PriceForRemote, 0)
+		}},
+		{GetUnloads, "unload_search.json", func(t *testing.T, path ApiPath, file string) {
+			var res UnloadsResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Unloads) != 1 {
+				t.Fatalf("got %+v", res.Unloads)
+			}
+			u := res.Unloads[0]
+			wantMoney(t, "sumPositions", u.SumPositions, 1200.10)
+			wantMoney(t, "sumWorks", u.SumWorks, 12.40)
+			wantMoney(t, "sumDelivery", u.SumDelivery, 0)
+			wantMoney(t, "sumTotal", u.SumTotal, 1212.5)
+			wantTime(t, "createTime", u.CreateTime, "2023-11-09 13:40:39")
+		}},
+		{GetUnloadData, "unload_get_data.json", func(t *testing.T, path ApiPath, file string) {
+			var res UnloadResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Unload.Boxes) != 1 || len(res.Unload.Positions) != 1 {
+				t.Fatalf("got %+v", res.Unload)
+			}
+			b := res.Unload.Boxes[0]
+			wantMoney(t, "boxes[0].sumPositions", b.SumPositions, 20.50)
+			wantMoney(t, "boxes[0].sumWorks", b.SumWorks, 0)
+			wantMoney(t, "boxes[0].length", b.Length, 30)
+			wantMoney(t, "boxes[0].height", b.Height, 10.25)
+			p := res.Unload.Positions[0]
+			wantMoney(t, "positions[0].price", p.Price, 10.25)
+			wantMoney(t, "positions[0].priceFinal", p.PriceFinal, 11.75)
+			wantMoney(t, "positions[0].weight", p.Weight, 0)
+		}},
+		{GetBoxesReady, "unload_get_boxes_ready.json", func(t *testing.T, path ApiPath, file string) {
+			var res BoxesReadyToSendResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.ReadyBoxes) != 1 {
+				t.Fatalf("got %+v", res.ReadyBoxes)
+			}
+			wantMoney(t, "sumPositions", res.ReadyBoxes[0].SumPositions, 1050)
+			wantMoney(t, "sumWorks", res.ReadyBoxes[0].SumWorks, 12.3)
+			wantMoney(t, "weight", res.ReadyBoxes[0].Weight, 0)
+		}},
+		{GetSuppliers, "info_get_suppliers.json", func(t *testing.T, path ApiPath, file string) {
+			var res SuppliersResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Suppliers) != 1 {
+				t.Fatalf("got %+v", res.Suppliers)
+			}
+			s := res.Suppliers[0]
+			if s.DeliveryHours.Int64 != 0 || !bool(s.IsReturn) || bool(s.IsPriceFinal) {
+				t.Errorf("got %+v", s)
+			}
+		}},
+		{GetBrands, "info_get_brands.json", func(t *testing.T, path ApiPath, file string) {
+			var res BrandsResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Brands) != 2 || bool(res.Brands[0].IsOriginal) || !bool(res.Brands[1].IsOriginal) {
+				t.Errorf("got %+v", res.Brands)
+			}
+		}},
+		{GetBrandGroups, "info_get_brand_groups.json", func(t *testing.T, path ApiPath, file string) {
+			var res BrandGroupsResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.BrandGroups) != 1 || len(res.BrandGroups[0].BrandIds) != 3 {
+				t.Fatalf("got %+v", res.BrandGroups)
+			}
+			ids := res.BrandGroups[0].BrandIds
+			if ids[0].Int != 12 || ids[1].Int != 13 || ids[2].Int != 0 {
+				t.Errorf("brandIds = %+v", ids)
+			}
+		}},
+		{GetProductInfo, "info_get_product_info.json", func(t *testing.T, path ApiPath, file string) {
+			var res ProductInfoResponse
+			decodeGolden(t, path, file, &res)
+			p := res.Data
+			wantMoney(t, "weight", p.Weight, 0.35)
+			wantMoney(t, "volume", p.Volume, 1.25)
+			if len(p.Images) != 2 || p.Images[1].Image != "https://img.example.com/1001-2.png" {
+				t.Errorf("images = %+v", p.Images)
+			}
+			if len(p.Properties) != 4 || p.Properties[1].Value != "3/4-16 UNF" ||
+				p.Properties[2].Value != "5" || p.Properties[3].Value != "true" {
+				t.Errorf("properties = %+v", p.Properties)
+			}
+			if len(p.Analogs) != 1 {
+				t.Fatalf("analogs = %+v", p.Analogs)
+			}
+			wantMoney(t, "analogs[0].weight", p.Analogs[0].Weight, 0.4)
+			wantMoney(t, "analogs[0].volume", p.Analogs[0].Volume, 0)
+		}},
+		{GetCurrencies, "info_get_currencies.json", func(t *testing.T, path ApiPath, file string) {
+			var res CurrenciesResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Currencies) != 3 {
+				t.Fatalf("got %+v", res.Currencies)
+			}
+			wantMoney(t, "USD", res.Currencies[0].Rate, 41.52)
+			wantMoney(t, "EUR", res.Currencies[1].Rate, 44.1)
+			wantMoney(t, "UAH", res.Currencies[2].Rate, 1)
+		}},
+		{GetBrandsByCode, "info_get_brands_by_code.json", func(t *testing.T, path ApiPath, file string) {
+			var res BrandsByCodeResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Data) != 1 || res.Data[0].BrandID != 12 || res.Data[0].OffersCount != 5 {
+				t.Errorf("got %+v", res.Data)
+			}
+		}},
+		{GetPositionStatuses, "info_get_position_statuses.json", func(t *testing.T, path ApiPath, file string) {
+			var res PositionStatusesResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Statuses) != 2 || res.Statuses[1].StatusID != 4 {
+				t.Errorf("got %+v", res.Statuses)
+			}
+		}},
+		{BasketAdd, "basket_add.json", func(t *testing.T, path ApiPath, file string) {
+			var res BasketAddResponse
+			decodeGolden(t, path, file, &res)
+			if res.Data.BasketID != 3301 {
+				t.Errorf("basketId = %d", res.Data.BasketID)
+			}
+		}},
+		{GetBasketPositions, "basket_get_positions.json", func(t *testing.T, path ApiPath, file string) {
+			var res BasketPositionsResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Positions) != 2 {
+				t.Fatalf("got %+v", res.Positions)
+			}
+			wantMoney(t, "positions[0].price", res.Positions[0].Price, 12.75)
+			wantMoney(t, "positions[1].price", res.Positions[1].Price, 1005.10)
+		}},
+		{BasketDeletePosition, "basket_delete.json", func(t *testing.T, path ApiPath, file string) {
+			var res SuccessResponse
+			decodeGolden(t, path, file, &res)
+			if !res.Success {
+				t.Error("success = false")
+			}
+		}},
+		{BasketClear, "basket_clear.json", func(t *testing.T, path ApiPath, file string) {
+			var res SuccessResponse
+			decodeGolden(t, path, file, &res)
+			if !res.Success {
+				t.Error("success = false")
+			}
+		}},
+		{GetPositionInfo, "order_get_position_info.json", func(t *testing.T, path ApiPath, file string) {
+			var res PositionsInfoResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Positions) != 1 || len(res.Positions[0].States) != 1 {
+				t.Fatalf("got %+v", res.Positions)
+			}
+			wantMoney(t, "price", res.Positions[0].Price, 99.99)
+			st := res.Positions[0].States[0]
+			if st.StatusID != 2 || st.Quantity != 2 {
+				t.Errorf("states[0] = %+v", st)
+			}
+			wantTime(t, "statusChangedDate", st.StatusChangedDate, "2023-11-09 13:45:00")
+		}},
+		{OrderCreate, "order_create.json", func(t *testing.T, path ApiPath, file string) {
+			var res OrderResponse
+			decodeGolden(t, path, file, &res)
+			wantMoney(t, "sum", res.Order.Sum, 1500.25)
+			if res.Order.StatusID.Int != 2 || res.Order.OrderNumber != "KYIV-20231109-0007" {
+				t.Errorf("got %+v", res.Order)
+			}
+			wantTime(t, "createTime", res.Order.CreateTime, "2023-11-09 13:40:39")
+		}},
+		{GetActiveOrders, "order_get_active.json", func(t *testing.T, path ApiPath, file string) {
+			var res OrdersResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Orders) != 2 {
+				t.Fatalf("got %+v", res.Orders)
+			}
+			wantMoney(t, "orders[0].sum", res.Orders[0].Sum, 1500.25)
+			wantMoney(t, "orders[1].sum", res.Orders[1].Sum, 0)
+			if res.Orders[1].StatusID.Int != 0 {
+				t.Errorf("orders[1].statusId = %d", res.Orders[1].StatusID.Int)
+			}
+			wantTime(t, "orders[1].createTime", res.Orders[1].CreateTime, "")
+		}},
+		{OrderSearch, "order_search.json", func(t *testing.T, path ApiPath, file string) {
+			var res OrdersResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Orders) != 1 {
+				t.Fatalf("got %+v", res.Orders)
+			}
+			wantMoney(t, "sum", res.Orders[0].Sum, 0.10)
+		}},
+		{GetChangedPositions, "order_get_changed_positions.json", func(t *testing.T, path ApiPath, file string) {
+			var res PositionsInfoResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Positions) != 1 || len(res.Positions[0].States) != 2 {
+				t.Fatalf("got %+v", res.Positions)
+			}
+			wantMoney(t, "price", res.Positions[0].Price, 10.25)
+			if res.Positions[0].States[1].StatusID != 5 {
+				t.Errorf("states[1] = %+v", res.Positions[0].States[1])
+			}
+		}},
+		{GetOrderPositions, "order_get_order_positions.json", func(t *testing.T, path ApiPath, file string) {
+			var res PositionsInfoResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Positions) != 1 {
+				t.Fatalf("got %+v", res.Positions)
+			}
+			wantMoney(t, "price", res.Positions[0].Price, 1010.05)
+		}},
+		{GetOrderPositionsByStatus, "order_get_order_positions_by_status.json", func(t *testing.T, path ApiPath, file string) {
+			var res PositionsInfoResponse
+			decodeGolden(t, path, file, &res)
+			if len(res.Positions) != 1 {
+				t.Fatalf("got %+v", res.Positions)
+			}
+			wantMoney(t, "price", res.Positions[0].Price, 0)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.path), func(t *testing.T) {
+			tt.check(t, tt.path, tt.file)
+		})
+	}
+}
+
+func TestDecodeGoldenError(t *testing.T) {
+	data := readGolden(t, "error.json")
+	err := checkSuccess(GetBasketPositions, http.StatusUnauthorized, data)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if apiErr.Name != "Unauthorized" || apiErr.Status != 401 || apiErr.Path != GetBasketPositions {
+		t.Errorf("got %+v", apiErr)
+	}
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("errors.Is(%v, ErrUnauthorized) = false", err)
+	}
+	var res ResponseError
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Success || res.Data.Message != "Your request was made with invalid credentials." {
+		t.Errorf("got %+v", res)
+	}
+}
@@ -0,0 +1,91 @@
+package tehnomir
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+	ErrValidation   = errors.New("validation failed")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// APIError ошибка, которую вернул API Техномира.
+type APIError struct {
+	HTTPStatus int           // HTTP статус ответа
+	Name       string        // data.name
+	Status     int           // data.status
+	Message    string        // data.message
+	Path       ApiPath       // метод API
+	Body       []byte        // сырое тело ответа
+	RetryAfter time.Duration // значение заголовка Retry-After, если был
+}
+
+func (e *APIError) Error() string {
+	if e.Name == "" && e.Message == "" {
+		return fmt.Sprintf("%s: http %d: %s", e.Path, e.HTTPStatus, string(e.Body))
+	}
+	return fmt.Sprintf("%s: %d:%s - %s", e.Path, e.Status, e.Name, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	return e.kind() == target
+}
+
+func (e *APIError) kind() error {
+	switch strings.ToLower(strings.ReplaceAll(e.Name, " ", "")) {
+	case "unauthorized", "unauthorizedhttpexception", "forbidden", "forbiddenhttpexception":
+		return ErrUnauthorized
+	case "notfound", "notfoundhttpexception":
+		return ErrNotFound
+	case "badrequest", "badrequesthttpexception", "unprocessableentity", "datavalidationfailed", "invalidargument":
+		return ErrValidation
+	case "toomanyrequests", "toomanyrequestshttpexception":
+		return ErrRateLimited
+	case "internalservererror", "servererror", "serviceunavailable":
+		return ErrServer
+	}
+	if e.HTTPStatus == http.StatusOK && e.Status == 0 {
+		// success:false без кода ошибки
+		return ErrBadResponse
+	}
+	status := e.Status
+	if status == 0 {
+		status = e.HTTPStatus
+	}
+	switch {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return ErrUnauthorized
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusBadRequest, status == http.StatusUnprocessableEntity:
+		return ErrValidation
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status >= http.StatusInternalServerError:
+		return ErrServer
+	}
+	return nil
+}
+
+func makeError(path ApiPath, httpStatus int, body []byte) *APIError {
+	apiErr := &APIError{
+		HTTPStatus: httpStatus,
+		Path:       path,
+		Body:       body,
+	}
+	var resp ResponseError
+	if err := json.Unmarshal(body, &resp); err == nil {
+		apiErr.Name = resp.Data.Name
+		apiErr.Status = resp.Data.Status
+		apiErr.Message = resp.Data.Message
+	}
+	return apiErr
+}
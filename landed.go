@@ -0,0 +1,164 @@
+package tehnomir
+
+import (
+	"context"
+	"strings"
+)
+
+type (
+	DeliveryMode string
+	CostBasis    string
+)
+
+const (
+	DeliveryLocal DeliveryMode = "local" // доставка включена в цену поставщика
+	DeliveryAvia  DeliveryMode = "avia"
+	DeliverySea   DeliveryMode = "sea"
+
+	BasisNone   CostBasis = "none"
+	BasisWeight CostBasis = "weight"
+	BasisVolume CostBasis = "volume"
+)
+
+// LandedCost стоимость единицы товара с доставкой. Все суммы в валюте предложения,
+// тарифы PriceAvia/PriceSea/PriceVolume должны быть заданы в этой же валюте.
+type LandedCost struct {
+	Mode    DeliveryMode
+	Basis   CostBasis
+	Weight  float64 // кг
+	Volume  float64 // объемный вес из GetProductInfo
+	Price   float64 // цена поставщика
+	Freight float64 // доставка
+	Total   float64
+}
+
+// LandedCostCalculator считает доставку по весу (PriceAvia/PriceSea за кг) или по объему
+// (PriceVolume), выбирая большее из двух.
+type LandedCostCalculator struct {
+	PriceAvia   float64
+	PriceSea    float64
+	PriceVolume float64
+	// DeliveryModes явное соответствие DeliveryTypeID -> тип доставки,
+	// если не задано - определяется по названию DeliveryType.
+	DeliveryModes map[int]DeliveryMode
+}
+
+func NewLandedCostCalculator(cfg *Config) *LandedCostCalculator {
+	lc := &LandedCostCalculator{
+		PriceAvia:     cfg.PriceAvia,
+		PriceSea:      cfg.PriceSea,
+		PriceVolume:   cfg.PriceVolume,
+		DeliveryModes: make(map[int]DeliveryMode),
+	}
+	if lc.PriceAvia == 0 {
+		lc.PriceAvia = PRICE_AVIA
+	}
+	if lc.PriceSea == 0 {
+		lc.PriceSea = PRICE_SEA
+	}
+	if lc.PriceVolume == 0 {
+		lc.PriceVolume = PRICE_VOLUME
+	}
+	return lc
+}
+
+func (lc *LandedCostCalculator) Mode(o *OfferSupplier) DeliveryMode {
+	if m, ok := lc.DeliveryModes[o.DeliveryTypeID]; ok {
+		return m
+	}
+	if o.IsPriceFinal {
+		return DeliveryLocal
+	}
+	t := strings.ToLower(o.DeliveryType)
+	switch {
+	case strings.Contains(t, "авиа"), strings.Contains(t, "avia"), strings.Contains(t, "air"):
+		return DeliveryAvia
+	case strings.Contains(t, "мор"), strings.Contains(t, "sea"), strings.Contains(t, "контейнер"):
+		return DeliverySea
+	}
+	return DeliveryLocal
+}
+
+// Calculate считает стоимость с доставкой для предложения. weight и volume на единицу товара,
+// volume может быть 0, если объем неизвестен.
+func (lc *LandedCostCalculator) Calculate(o *OfferSupplier, weight, volume float64) LandedCost {
+	cost := LandedCost{
+		Mode:   lc.Mode(o),
+		Basis:  BasisNone,
+		Weight: weight,
+		Volume: volume,
+		Price:  o.Price.Float64,
+	}
+	var rate float64
+	switch cost.Mode {
+	case DeliveryAvia:
+		rate = lc.PriceAvia
+	case DeliverySea:
+		rate = lc.PriceSea
+	}
+	if rate > 0 {
+		byWeight := weight * rate
+		byVolume := volume * lc.PriceVolume
+		cost.Basis, cost.Freight = BasisWeight, byWeight
+		if byVolume > byWeight {
+			cost.Basis, cost.Freight = BasisVolume, byVolume
+		}
+	}
+	cost.Total = cost.Price + cost.Freight
+	return cost
+}
+
+func (c *Client) LandedCost(lc *LandedCostCalculator, d *FoundDetail, o *OfferSupplier) (*LandedCost, error) {
+	return c.LandedCostCtx(context.Background(), lc, d, o)
+}
+
+// LandedCostCtx считает стоимость с доставкой, запрашивая объем (и вес, если его нет
+// в результате поиска) через GetProductInfo.
+func (c *Client) LandedCostCtx(ctx context.Context, lc *LandedCostCalculator, d *FoundDetail, o *OfferSupplier) (*LandedCost, error) {
+	weight, volume, err := c.weightAndVolume(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	cost := lc.Calculate(o, weight, volume)
+	return &cost, nil
+}
+
+func (c *Client) LandedCosts(lc *LandedCostCalculator, res *PriceSearchResponse) ([][]LandedCost, error) {
+	return c.LandedCostsCtx(context.Background(), lc, res)
+}
+
+// LandedCostsCtx считает стоимость с доставкой для всех предложений из результата поиска.
+// Результат в том же порядке, что и Details/Stocks.
+func (c *Client) LandedCostsCtx(ctx context.Context, lc *LandedCostCalculator, res *PriceSearchResponse) ([][]LandedCost, error) {
+	costs := make([][]LandedCost, len(res.Details))
+	for i := range res.Details {
+		d := &res.Details[i]
+		if len(d.Stocks) == 0 {
+			continue
+		}
+		weight, volume, err := c.weightAndVolume(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		costs[i] = make([]LandedCost, len(d.Stocks))
+		for j := range d.Stocks {
+			costs[i][j] = lc.Calculate(&d.Stocks[j], weight, volume)
+		}
+	}
+	return costs, nil
+}
+
+func (c *Client) weightAndVolume(ctx context.Context, d *FoundDetail) (float64, float64, error) {
+	weight := d.Weight.Float64
+	if !d.IsExistProductInfo {
+		return weight, 0, nil
+	}
+	info, err := c.GetProductInfoCtx(ctx, d.Code, d.BrandID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if weight == 0 {
+		weight = info.Data.Weight.Float64
+	}
+	return weight, info.Data.Volume.Float64, nil
+}
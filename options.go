@@ -0,0 +1,55 @@
+package tehnomir
+
+import "net/http"
+
+// Option настраивает Client при создании через New.
+type Option func(*Client)
+
+// WithHTTPClient подменяет http.Client целиком, таймауты и транспорт из Config
+// в этом случае не применяются.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.client = hc
+	}
+}
+
+// WithTransport задает собственный RoundTripper (прокси, mTLS, трассировка),
+// общий таймаут по-прежнему берется из Config.Timeout.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
+
+// WithRetryPolicy задает политику повторов запросов, NoRetry() отключает повторы.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// WithRateLimiter включает ограничение частоты запросов. Один RateLimiter
+// можно передать нескольким клиентам с одним токеном.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
+// WithCache задает хранилище и настройки кэша справочников. По умолчанию
+// используется MemoryCache с DefaultCacheConfig, store nil отключает кэш.
+func WithCache(store Cache, cfg CacheConfig) Option {
+	return func(c *Client) {
+		if store == nil {
+			c.cache = nil
+			return
+		}
+		if cfg.TTL <= 0 {
+			cfg.TTL = CACHE_TTL
+		}
+		c.cache = &referenceCache{
+			store: store,
+			cfg:   cfg,
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package tehnomir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const ORDER_NUMBER_ATTEMPTS = 5
+
+var ErrDuplicateOrder = errors.New("order number already used")
+
+// DuplicateOrderError номер заказа уже использован.
+type DuplicateOrderError struct {
+	OrderNumber string
+	Existing    Order
+}
+
+func (e *DuplicateOrderError) Error() string {
+	return fmt.Sprintf("%v: %s (order id %d, created %s)", ErrDuplicateOrder, e.OrderNumber,
+		e.Existing.OrderID, time.Time(e.Existing.CreateTime).Format("2006-01-02 15:04:05"))
+}
+
+func (e *DuplicateOrderError) Unwrap() error {
+	return ErrDuplicateOrder
+}
+
+// OrderNumberGenerator источник номеров заказов.
+type OrderNumberGenerator interface {
+	Next(ctx context.Context) (string, error)
+}
+
+// CounterStore хранилище счетчиков, Increment возвращает новое значение.
+type CounterStore interface {
+	Increment(ctx context.Context, key string) (int64, error)
+}
+
+// SequenceGenerator номера вида <Prefix><Separator><дата><Separator><счетчик>,
+// например "KYIV-20231109-0007". Счетчик ведется отдельно на каждую дату.
+type SequenceGenerator struct {
+	Prefix     string
+	DateLayout string // по умолчанию "20060102"
+	Separator  string // по умолчанию "-"
+	Width      int    // минимальная ширина счетчика с ведущими нулями, по умолчанию 4
+
+	store CounterStore
+	now   func() time.Time
+}
+
+func NewSequenceGenerator(prefix string, store CounterStore) *SequenceGenerator {
+	return &SequenceGenerator{
+		Prefix:     prefix,
+		DateLayout: "20060102",
+		Separator:  "-",
+		Width:      4,
+		store:      store,
+		now:        time.Now,
+	}
+}
+
+func (g *SequenceGenerator) Next(ctx context.Context) (string, error) {
+	parts := make([]string, 0, 3)
+	if g.Prefix != "" {
+		parts = append(parts, g.Prefix)
+	}
+	if g.DateLayout != "" {
+		parts = append(parts, g.now().Format(g.DateLayout))
+	}
+	key := strings.Join(parts, g.Separator)
+	n, err := g.store.Increment(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append(parts, fmt.Sprintf("%0*d", g.Width, n)), g.Separator), nil
+}
+
+type MemoryCounterStore struct {
+	mu       sync.Mutex
+	counters map[string]int64
+}
+
+func NewMemoryCounterStore() *MemoryCounterStore {
+	return &MemoryCounterStore{counters: make(map[string]int64)}
+}
+
+func (s *MemoryCounterStore) Increment(_ context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counters[key]++
+	return s.counters[key], nil
+}
+
+// FileCounterStore хранит счетчики в JSON файле, значение переживает перезапуск.
+// Рассчитан на один процесс.
+type FileCounterStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileCounterStore(path string) *FileCounterStore {
+	return &FileCounterStore{path: path}
+}
+
+func (s *FileCounterStore) Increment(_ context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counters := make(map[string]int64)
+	data, err := os.ReadFile(s.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return 0, err
+	default:
+		if err := json.Unmarshal(data, &counters); err != nil {
+			return 0, fmt.Errorf("counter store %s: %w", s.path, err)
+		}
+	}
+	counters[key]++
+	if data, err = json.Marshal(counters); err != nil {
+		return 0, err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return 0, err
+	}
+	return counters[key], nil
+}
+
+// OrderCreateUnique создает заказ, предварительно проверив через OrderSearchByNumber,
+// что номер не использован. При совпадении возвращает *DuplicateOrderError.
+func (c *Client) OrderCreateUnique(ordernum string) (*OrderResponse, error) {
+	return c.OrderCreateUniqueCtx(context.Background(), ordernum)
+}
+
+func (c *Client) OrderCreateUniqueCtx(ctx context.Context, ordernum string) (*OrderResponse, error) {
+	orders, err := c.OrderSearchByNumberCtx(ctx, ordernum)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		for _, o := range orders.Orders {
+			if strings.EqualFold(o.OrderNumber, ordernum) {
+				return nil, &DuplicateOrderError{OrderNumber: ordernum, Existing: o}
+			}
+		}
+	}
+	return c.OrderCreateCtx(ctx, ordernum)
+}
+
+// OrderCreateNext создает заказ с номером из генератора, при занятом номере берет
+// следующий, не более ORDER_NUMBER_ATTEMPTS раз.
+func (c *Client) OrderCreateNext(gen OrderNumberGenerator) (*OrderResponse, error) {
+	return c.OrderCreateNextCtx(context.Background(), gen)
+}
+
+func (c *Client) OrderCreateNextCtx(ctx context.Context, gen OrderNumberGenerator) (*OrderResponse, error) {
+	var lastErr error
+	for i := 0; i < ORDER_NUMBER_ATTEMPTS; i++ {
+		ordernum, err := gen.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.OrderCreateUniqueCtx(ctx, ordernum)
+		if !errors.Is(err, ErrDuplicateOrder) {
+			return res, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,165 @@
+// Package partnum нормализует номера запчастей для сравнения и форматирует их для показа.
+package partnum
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// кириллические буквы, похожие на латинские
+var lookalikes = map[rune]rune{
+	'А': 'A', 'В': 'B', 'Е': 'E', 'Ё': 'E', 'К': 'K', 'М': 'M', 'Н': 'H',
+	'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T', 'У': 'Y', 'Х': 'X', 'І': 'I',
+	'Ї': 'I',
+}
+
+// Rule правила нормализации и отображения номеров бренда.
+type Rule struct {
+	// StripLeadingZeros убирает ведущие нули ("0986452041" и "986452041" равны).
+	StripLeadingZeros bool
+	// StripPrefixes убирает префиксы, которые бренд добавляет к номеру (например "A" у Mercedes).
+	StripPrefixes []string
+	// Groups длины групп символов для отображения, например {1, 3, 3, 3} для BOSCH:
+	// "0986452041" -> "0 986 452 041". Остаток выводится последней группой.
+	Groups []int
+	// Separator разделитель групп, по умолчанию пробел.
+	Separator string
+}
+
+// Normalizer нормализует номера по правилам брендов. Безопасен для использования
+// из нескольких горутин.
+type Normalizer struct {
+	mu    sync.RWMutex
+	def   Rule
+	rules map[string]Rule
+}
+
+func New() *Normalizer {
+	return &Normalizer{
+		rules: make(map[string]Rule),
+	}
+}
+
+// SetDefault задает правило для брендов без собственного правила.
+func (n *Normalizer) SetDefault(r Rule) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.def = r
+}
+
+func (n *Normalizer) SetRule(brand string, r Rule) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.rules[brandKey(brand)] = r
+}
+
+func (n *Normalizer) rule(brand string) Rule {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if r, ok := n.rules[brandKey(brand)]; ok {
+		return r
+	}
+	return n.def
+}
+
+// Normalize приводит номер к виду для сравнения по правилу бренда.
+func (n *Normalizer) Normalize(brand, code string) string {
+	r := n.rule(brand)
+	s := Clean(code)
+	for _, p := range r.StripPrefixes {
+		if p = Clean(p); p != "" && strings.HasPrefix(s, p) && len(s) > len(p) {
+			s = s[len(p):]
+			break
+		}
+	}
+	if r.StripLeadingZeros {
+		if t := strings.TrimLeft(s, "0"); t != "" {
+			s = t
+		}
+	}
+	return s
+}
+
+// Format форматирует номер для показа по группам правила бренда.
+// Без групп возвращает очищенный номер.
+func (n *Normalizer) Format(brand, code string) string {
+	r := n.rule(brand)
+	s := []rune(Clean(code))
+	if len(r.Groups) == 0 {
+		return string(s)
+	}
+	sep := r.Separator
+	if sep == "" {
+		sep = " "
+	}
+	var parts []string
+	for _, g := range r.Groups {
+		if len(s) == 0 {
+			break
+		}
+		if g > len(s) {
+			g = len(s)
+		}
+		parts = append(parts, string(s[:g]))
+		s = s[g:]
+	}
+	if len(s) > 0 {
+		parts = append(parts, string(s))
+	}
+	return strings.Join(parts, sep)
+}
+
+// Equal сравнивает номера одного бренда после нормализации.
+func (n *Normalizer) Equal(brand, a, b string) bool {
+	return n.Normalize(brand, a) == n.Normalize(brand, b)
+}
+
+// Compare возвращает -1, 0, 1 для нормализованных номеров, годится для сортировки.
+func (n *Normalizer) Compare(brand, a, b string) int {
+	return strings.Compare(n.Normalize(brand, a), n.Normalize(brand, b))
+}
+
+// Clean переводит номер в верхний регистр, заменяет кириллические буквы, похожие
+// на латинские, и удаляет все, кроме букв и цифр.
+func Clean(code string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToUpper(code) {
+		if l, ok := lookalikes[r]; ok {
+			r = l
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+var std = New()
+
+// Default возвращает общий Normalizer пакета.
+func Default() *Normalizer {
+	return std
+}
+
+func Normalize(brand, code string) string {
+	return std.Normalize(brand, code)
+}
+
+func Format(brand, code string) string {
+	return std.Format(brand, code)
+}
+
+func Equal(brand, a, b string) bool {
+	return std.Equal(brand, a, b)
+}
+
+func brandKey(brand string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToUpper(brand) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,26 @@
+package tehnomir
+
+import "github.com/NuclearLouse/tehnomir/partnum"
+
+// SameCode сравнивает номер позиции (приходит неочищенный) и номер замены с code
+// по правилам partnum для бренда позиции.
+func (p *Position) SameCode(code string) bool {
+	if partnum.Equal(p.Brand, p.Code, code) {
+		return true
+	}
+	return p.ReplaceCode != "" && partnum.Equal(p.Brand, p.ReplaceCode, code)
+}
+
+func (s PositionStatus) String() string {
+	return s.Status
+}
+
+// Status ищет статус по ID.
+func (r *PositionStatusesResponse) Status(statusID PositionStatusID) (PositionStatus, bool) {
+	for _, s := range r.Statuses {
+		if s.StatusID == statusID {
+			return s, true
+		}
+	}
+	return PositionStatus{}, false
+}
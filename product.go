@@ -0,0 +1,347 @@
+package tehnomir
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/NuclearLouse/tehnomir/utilits"
+)
+
+const IMAGE_DOWNLOAD_CONCURRENCY = 4
+
+var ErrNotImage = errors.New("not an image")
+
+// ProductProperty характеристика товара.
+type ProductProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Unit  string `json:"unit,omitempty"`
+}
+
+// UnmarshalJSON принимает объект {"name","value","unit"} (также "property"/"title"
+// для названия), пару ["name", "value"] или строку "name: value".
+func (p *ProductProperty) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	switch data[0] {
+	case '{':
+		var raw map[string]any
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("ProductProperty: UnmarshalJSON: %w", err)
+		}
+		p.Name = firstString(raw, "name", "property", "title")
+		p.Value = firstString(raw, "value", "val")
+		p.Unit = firstString(raw, "unit", "units")
+	case '[':
+		var pair []any
+		if err := json.Unmarshal(data, &pair); err != nil {
+			return fmt.Errorf("ProductProperty: UnmarshalJSON: %w", err)
+		}
+		if len(pair) > 0 {
+			p.Name = anyString(pair[0])
+		}
+		if len(pair) > 1 {
+			p.Value = anyString(pair[1])
+		}
+		if len(pair) > 2 {
+			p.Unit = anyString(pair[2])
+		}
+	default:
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("ProductProperty: UnmarshalJSON: %w", err)
+		}
+		s, ok := v.(string)
+		if !ok {
+			// число или bool без названия
+			p.Value = anyString(v)
+			return nil
+		}
+		name, value, ok := strings.Cut(s, ":")
+		if !ok {
+			p.Value = strings.TrimSpace(s)
+			return nil
+		}
+		p.Name, p.Value = strings.TrimSpace(name), strings.TrimSpace(value)
+	}
+	return nil
+}
+
+func firstString(m map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if v, ok := m[k]; ok && v != nil {
+			return anyString(v)
+		}
+	}
+	return ""
+}
+
+func anyString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case nil:
+		return ""
+	case float64:
+		return fmt.Sprint(t)
+	}
+	return fmt.Sprint(v)
+}
+
+// ProductImage изображение товара, путь может быть относительным.
+type ProductImage struct {
+	Image string `json:"image"`
+}
+
+// UnmarshalJSON принимает объект {"image": "..."} или просто строку.
+func (pi *ProductImage) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == utilits.QUOTES_BYTE {
+		return json.Unmarshal(data, &pi.Image)
+	}
+	var obj struct {
+		Image string `json:"image"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("ProductImage: UnmarshalJSON: %w", err)
+	}
+	pi.Image = obj.Image
+	return nil
+}
+
+// ResolveImageURL строит абсолютный URL изображения относительно Proto и Host клиента.
+func (c *Client) ResolveImageURL(image string) string {
+	image = strings.TrimSpace(image)
+	if image == "" {
+		return ""
+	}
+	u, err := url.Parse(image)
+	if err == nil && u.IsAbs() {
+		return image
+	}
+	base := &url.URL{Scheme: c.cfg.Proto, Host: c.cfg.Host, Path: "/"}
+	if err != nil {
+		return base.String() + strings.TrimPrefix(image, "/")
+	}
+	return base.ResolveReference(u).String()
+}
+
+// ImageURLs абсолютные URL изображений товара без повторов.
+func (c *Client) ImageURLs(info *ProductInfoResponse) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, img := range info.Data.Images {
+		u := c.ResolveImageURL(img.Image)
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+type DownloadedImage struct {
+	URL         string
+	Path        string // файл в каталоге, пусто при загрузке в io.Writer
+	ContentType string
+	SHA256      string
+	Duplicate   bool // такое же содержимое уже было загружено
+	Err         error
+}
+
+// ImageDownloader загружает изображения товаров параллельно, проверяя тип содержимого
+// и пропуская повторы по URL и по содержимому.
+type ImageDownloader struct {
+	Concurrency int
+	MaxSize     int64 // 0 - без ограничения
+
+	client *http.Client
+
+	mu   sync.Mutex
+	seen map[string]string // sha256 -> путь
+}
+
+func (c *Client) NewImageDownloader() *ImageDownloader {
+	return &ImageDownloader{
+		Concurrency: IMAGE_DOWNLOAD_CONCURRENCY,
+		client:      c.client,
+		seen:        make(map[string]string),
+	}
+}
+
+// DownloadToDir загружает изображения в каталог dir. Имя файла - sha256 содержимого,
+// поэтому одинаковые изображения записываются один раз. Результаты в порядке urls.
+func (d *ImageDownloader) DownloadToDir(ctx context.Context, dir string, urls ...string) ([]DownloadedImage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	results := make([]DownloadedImage, len(urls))
+	first := make(map[string]int)
+	concurrency := d.Concurrency
+	if concurrency <= 0 {
+		concurrency = IMAGE_DOWNLOAD_CONCURRENCY
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		if _, ok := first[u]; ok {
+			continue
+		}
+		first[u] = i
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = d.downloadFile(ctx, dir, u)
+		}(i, u)
+	}
+	wg.Wait()
+	for i, u := range urls {
+		if j := first[u]; j != i {
+			results[i] = results[j]
+			results[i].Duplicate = true
+		}
+	}
+	return results, nil
+}
+
+func (d *ImageDownloader) downloadFile(ctx context.Context, dir, u string) DownloadedImage {
+	res := DownloadedImage{URL: u}
+	var buf bytes.Buffer
+	res.ContentType, res.Err = d.Download(ctx, u, &buf)
+	if res.Err != nil {
+		return res
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	res.SHA256 = hex.EncodeToString(sum[:])
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if path, ok := d.seen[res.SHA256]; ok {
+		res.Path = path
+		res.Duplicate = true
+		return res
+	}
+	res.Path = filepath.Join(dir, res.SHA256+imageExt(res.ContentType))
+	if res.Err = os.WriteFile(res.Path, buf.Bytes(), 0o644); res.Err != nil {
+		return res
+	}
+	d.seen[res.SHA256] = res.Path
+	return res
+}
+
+// Download загружает изображение в w и возвращает его тип. Если ответ не является
+// изображением, возвращает ErrNotImage, в w ничего не пишется.
+func (d *ImageDownloader) Download(ctx context.Context, u string, w io.Writer) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: http %d", u, resp.StatusCode)
+	}
+	var body io.Reader = resp.Body
+	if d.MaxSize > 0 {
+		body = io.LimitReader(resp.Body, d.MaxSize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	if d.MaxSize > 0 && int64(len(data)) > d.MaxSize {
+		return "", fmt.Errorf("%s: image larger than %d bytes", u, d.MaxSize)
+	}
+	sniffed := http.DetectContentType(data)
+	declared, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(sniffed, "image/") && !strings.HasPrefix(declared, "image/") {
+		return "", fmt.Errorf("%w: %s: %s", ErrNotImage, u, sniffed)
+	}
+	contentType := sniffed
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = declared
+	}
+	if _, err := w.Write(data); err != nil {
+		return "", err
+	}
+	return contentType, nil
+}
+
+func imageExt(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "image/bmp":
+		return ".bmp"
+	case "image/svg+xml":
+		return ".svg"
+	}
+	return ""
+}
+
+// HasAnalogs true, если у товара есть аналоги.
+func (p *Product) HasAnalogs() bool {
+	return len(p.Analogs) > 0
+}
+
+// FindAnalog ищет аналог по бренду и номеру.
+func (p *Product) FindAnalog(brand, code string) (Analog, bool) {
+	for _, a := range p.Analogs {
+		if a.Is(brand, code) {
+			return a, true
+		}
+	}
+	return Analog{}, false
+}
+
+// AnalogsByBrand аналоги указанного бренда.
+func (p *Product) AnalogsByBrand(brand string) []Analog {
+	var analogs []Analog
+	for _, a := range p.Analogs {
+		if NormalizeBrandName(a.Brand) == NormalizeBrandName(brand) {
+			analogs = append(analogs, a)
+		}
+	}
+	return analogs
+}
+
+// Property ищет характеристику по названию без учета регистра.
+func (p *Product) Property(name string) (ProductProperty, bool) {
+	for _, prop := range p.Properties {
+		if strings.EqualFold(prop.Name, name) {
+			return prop, true
+		}
+	}
+	return ProductProperty{}, false
+}
+
+// Is сравнивает бренд и номер аналога без учета регистра и знаков препинания.
+func (a Analog) Is(brand, code string) bool {
+	return NormalizeBrandName(a.Brand) == NormalizeBrandName(brand) && normCode(a.Code) == normCode(code)
+}
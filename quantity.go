@@ -0,0 +1,154 @@
+package tehnomir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrQuantityInvalid      = errors.New("quantity must be positive")
+	ErrQuantityNotMultiple  = errors.New("quantity is not a multiple of offer multiplicity")
+	ErrQuantityExceedsStock = errors.New("quantity exceeds offer stock")
+)
+
+// QuantityError ошибка проверки количества для предложения.
+type QuantityError struct {
+	Reason       error // одна из ErrQuantity*
+	PriceLogo    string
+	Requested    int
+	Suggested    int // допустимое количество, если его удалось подобрать
+	Multiplicity int
+	Available    int64 // 0 - остаток неизвестен
+}
+
+func (e *QuantityError) Error() string {
+	msg := fmt.Sprintf("%v: requested %d, multiplicity %d, available %d",
+		e.Reason, e.Requested, e.Multiplicity, e.Available)
+	if e.PriceLogo != "" {
+		msg = e.PriceLogo + ": " + msg
+	}
+	return msg
+}
+
+func (e *QuantityError) Unwrap() error {
+	return e.Reason
+}
+
+// QuantityAllocation часть количества, взятая у одного предложения.
+type QuantityAllocation struct {
+	Offer    *OfferSupplier
+	Quantity int
+}
+
+func multiplicity(o *OfferSupplier) int {
+	if o.Multiplicity > 1 {
+		return o.Multiplicity
+	}
+	return 1
+}
+
+// ValidateQuantity проверяет, что qty кратно Multiplicity и не больше остатка Quantity.
+// Нулевой остаток считается неизвестным и не ограничивает количество.
+func ValidateQuantity(o *OfferSupplier, qty int) error {
+	m := multiplicity(o)
+	qerr := &QuantityError{
+		PriceLogo:    o.PriceLogo,
+		Requested:    qty,
+		Multiplicity: m,
+		Available:    o.Quantity.Int64,
+	}
+	switch avail := o.Quantity.Int64; {
+	case qty <= 0:
+		qerr.Reason = ErrQuantityInvalid
+	case qty%m != 0:
+		qerr.Reason = ErrQuantityNotMultiple
+		qerr.Suggested = roundUp(qty, m)
+		if avail > 0 && int64(qerr.Suggested) > avail {
+			qerr.Suggested = int(avail) / m * m
+		}
+	case avail > 0 && int64(qty) > avail:
+		qerr.Reason = ErrQuantityExceedsStock
+		qerr.Suggested = int(avail) / m * m
+	default:
+		return nil
+	}
+	return qerr
+}
+
+// RoundQuantity округляет qty вверх до кратности предложения и проверяет остаток.
+func RoundQuantity(o *OfferSupplier, qty int) (int, error) {
+	return roundQuantity(o, qty)
+}
+
+func roundQuantity(o *OfferSupplier, qty int) (int, error) {
+	m := multiplicity(o)
+	qerr := &QuantityError{
+		PriceLogo:    o.PriceLogo,
+		Requested:    qty,
+		Multiplicity: m,
+		Available:    o.Quantity.Int64,
+	}
+	if qty <= 0 {
+		qerr.Reason = ErrQuantityInvalid
+		return 0, qerr
+	}
+	rounded := roundUp(qty, m)
+	if avail := o.Quantity.Int64; avail > 0 && int64(rounded) > avail {
+		qerr.Reason = ErrQuantityExceedsStock
+		qerr.Suggested = int(avail) / m * m
+		return 0, qerr
+	}
+	return rounded, nil
+}
+
+func roundUp(qty, m int) int {
+	return (qty + m - 1) / m * m
+}
+
+// SplitQuantity распределяет qty по предложениям в заданном порядке (обычно после Ranker),
+// соблюдая кратность и остатки. Если покрыть количество не удалось, возвращает
+// частичное распределение и QuantityError с ErrQuantityExceedsStock.
+// Итоговое количество может превышать qty из-за округления до кратности.
+func SplitQuantity(offers []*OfferSupplier, qty int) ([]QuantityAllocation, error) {
+	if qty <= 0 {
+		return nil, &QuantityError{Reason: ErrQuantityInvalid, Requested: qty}
+	}
+	var allocs []QuantityAllocation
+	left := qty
+	for _, o := range offers {
+		if left <= 0 {
+			break
+		}
+		m := multiplicity(o)
+		take := roundUp(left, m)
+		if avail := o.Quantity.Int64; avail > 0 && int64(take) > avail {
+			take = int(avail) / m * m
+		}
+		if take <= 0 {
+			continue
+		}
+		allocs = append(allocs, QuantityAllocation{Offer: o, Quantity: take})
+		left -= take
+	}
+	if left > 0 {
+		return allocs, &QuantityError{
+			Reason:    ErrQuantityExceedsStock,
+			Requested: qty,
+			Suggested: qty - left,
+		}
+	}
+	return allocs, nil
+}
+
+// BasketAddOffer добавляет предложение в корзину, предварительно проверив количество.
+func (c *Client) BasketAddOffer(prodid int64, o *OfferSupplier, quantity int, reference string, comment ...string) (*BasketAddResponse, error) {
+	return c.BasketAddOfferCtx(context.Background(), prodid, o, quantity, reference, comment...)
+}
+
+func (c *Client) BasketAddOfferCtx(ctx context.Context, prodid int64, o *OfferSupplier, quantity int, reference string, comment ...string) (*BasketAddResponse, error) {
+	if err := ValidateQuantity(o, quantity); err != nil {
+		return nil, err
+	}
+	return c.BasketAddCtx(ctx, prodid, o.PriceLogo, quantity, reference, comment...)
+}
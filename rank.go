@@ -0,0 +1,236 @@
+package tehnomir
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/NuclearLouse/tehnomir/partnum"
+)
+
+// OfferFilter жесткий фильтр предложений, false - предложение отбрасывается.
+type OfferFilter func(d *FoundDetail, o *OfferSupplier) bool
+
+func OnlyOriginals() OfferFilter {
+	return func(d *FoundDetail, _ *OfferSupplier) bool {
+		return bool(d.IsOriginal)
+	}
+}
+
+func OnlyReturnable() OfferFilter {
+	return func(_ *FoundDetail, o *OfferSupplier) bool {
+		return bool(o.IsReturn)
+	}
+}
+
+func MaxDeliveryDays(days int) OfferFilter {
+	return func(_ *FoundDetail, o *OfferSupplier) bool {
+		return o.DeliveryDays <= days
+	}
+}
+
+func MinDeliveryPercent(percent int) OfferFilter {
+	return func(_ *FoundDetail, o *OfferSupplier) bool {
+		return o.DeliveryPercent >= percent
+	}
+}
+
+// MinQuantity оставляет предложения, где на складе не меньше qty.
+// Нулевой остаток считается неизвестным и фильтр проходит, как в ValidateQuantity.
+func MinQuantity(qty int64) OfferFilter {
+	return func(_ *FoundDetail, o *OfferSupplier) bool {
+		return o.Quantity.Int64 <= 0 || o.Quantity.Int64 >= qty
+	}
+}
+
+// OnlyPriceLogos оставляет предложения только указанных поставщиков.
+func OnlyPriceLogos(logos ...string) OfferFilter {
+	return func(_ *FoundDetail, o *OfferSupplier) bool {
+		for _, l := range logos {
+			if strings.EqualFold(l, o.PriceLogo) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RankWeights веса критериев. Каждый критерий нормируется в [0, 1], где 1 - лучшее
+// значение среди сравниваемых предложений, итоговая оценка - взвешенная сумма.
+type RankWeights struct {
+	Price           float64 // дешевле - лучше
+	DeliveryDays    float64 // быстрее - лучше
+	DeliveryPercent float64 // процент поставки, больше - лучше
+	PriceQuality    float64 // больше - лучше
+	Return          float64 // возможен возврат
+	Quantity        float64 // больше наличие - лучше
+	Multiplicity    float64 // меньше кратность - лучше
+}
+
+func DefaultRankWeights() RankWeights {
+	return RankWeights{
+		Price:           1.0,
+		DeliveryDays:    0.5,
+		DeliveryPercent: 0.5,
+		PriceQuality:    0.2,
+		Return:          0.1,
+		Quantity:        0.1,
+		Multiplicity:    0.1,
+	}
+}
+
+type RankedOffer struct {
+	Detail *FoundDetail
+	Offer  *OfferSupplier
+	Score  float64
+}
+
+type Ranker struct {
+	Weights RankWeights
+	Filters []OfferFilter
+}
+
+func NewRanker(weights RankWeights, filters ...OfferFilter) *Ranker {
+	return &Ranker{
+		Weights: weights,
+		Filters: filters,
+	}
+}
+
+// Rank отбирает предложения по фильтрам и сортирует их по убыванию оценки.
+// Если заданы codes, учитываются только детали с этими номерами.
+func (r *Ranker) Rank(res *PriceSearchResponse, codes ...string) []RankedOffer {
+	if res == nil {
+		return nil
+	}
+	want := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		want[normCode(code)] = true
+	}
+	var offers []RankedOffer
+	for i := range res.Details {
+		d := &res.Details[i]
+		if len(want) > 0 && !want[normCode(d.Code)] {
+			continue
+		}
+	stocks:
+		for j := range d.Stocks {
+			o := &d.Stocks[j]
+			for _, f := range r.Filters {
+				if !f(d, o) {
+					continue stocks
+				}
+			}
+			offers = append(offers, RankedOffer{Detail: d, Offer: o})
+		}
+	}
+	r.score(offers)
+	sort.SliceStable(offers, func(i, j int) bool {
+		return offers[i].Score > offers[j].Score
+	})
+	return offers
+}
+
+// Top возвращает n лучших предложений.
+func (r *Ranker) Top(res *PriceSearchResponse, n int, codes ...string) []RankedOffer {
+	offers := r.Rank(res, codes...)
+	if n > 0 && len(offers) > n {
+		offers = offers[:n]
+	}
+	return offers
+}
+
+// TopByCode возвращает n лучших предложений для каждого номера детали из ответа.
+// Ключ - номер, очищенный partnum.Clean.
+func (r *Ranker) TopByCode(res *PriceSearchResponse, n int) map[string][]RankedOffer {
+	top := make(map[string][]RankedOffer)
+	for _, o := range r.Rank(res) {
+		code := normCode(o.Detail.Code)
+		if n > 0 && len(top[code]) >= n {
+			continue
+		}
+		top[code] = append(top[code], o)
+	}
+	return top
+}
+
+func (r *Ranker) score(offers []RankedOffer) {
+	if len(offers) == 0 {
+		return
+	}
+	price := newSpan()
+	days := newSpan()
+	quality := newSpan()
+	qty := newSpan()
+	for _, o := range offers {
+		price.add(o.Offer.Price.Float64)
+		days.add(float64(o.Offer.DeliveryDays))
+		quality.add(o.Offer.PriceQuality)
+		qty.add(float64(o.Offer.Quantity.Int64))
+	}
+	w := r.Weights
+	for i := range offers {
+		o := offers[i].Offer
+		var s float64
+		s += w.Price * price.lowerBetter(o.Price.Float64)
+		s += w.DeliveryDays * days.lowerBetter(float64(o.DeliveryDays))
+		s += w.DeliveryPercent * clamp01(float64(o.DeliveryPercent)/100)
+		s += w.PriceQuality * quality.higherBetter(o.PriceQuality)
+		if o.IsReturn {
+			s += w.Return
+		}
+		s += w.Quantity * qty.higherBetter(float64(o.Quantity.Int64))
+		if o.Multiplicity > 1 {
+			s += w.Multiplicity / float64(o.Multiplicity)
+		} else {
+			s += w.Multiplicity
+		}
+		offers[i].Score = s
+	}
+}
+
+type span struct {
+	min, max float64
+	empty    bool
+}
+
+func newSpan() *span {
+	return &span{empty: true}
+}
+
+func (s *span) add(v float64) {
+	if s.empty || v < s.min {
+		s.min = v
+	}
+	if s.empty || v > s.max {
+		s.max = v
+	}
+	s.empty = false
+}
+
+func (s *span) lowerBetter(v float64) float64 {
+	if s.max == s.min {
+		return 1
+	}
+	return (s.max - v) / (s.max - s.min)
+}
+
+func (s *span) higherBetter(v float64) float64 {
+	if s.max == s.min {
+		return 1
+	}
+	return (v - s.min) / (s.max - s.min)
+}
+
+func clamp01(v float64) float64 {
+	switch {
+	case v < 0:
+		return 0
+	case v > 1:
+		return 1
+	}
+	return v
+}
+
+func normCode(code string) string {
+	return partnum.Clean(code)
+}
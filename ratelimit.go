@@ -0,0 +1,138 @@
+package tehnomir
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit лимит запросов: Rate запросов в секунду с допустимым всплеском Burst.
+// Нулевой Rate означает отсутствие ограничения.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimiter ограничивает частоту запросов к API (token bucket) глобально и по отдельным методам.
+// Безопасен для использования из нескольких горутин.
+type RateLimiter struct {
+	// RespectRetryAfter приостанавливает все запросы на время из заголовка Retry-After.
+	RespectRetryAfter bool
+
+	mu           sync.Mutex
+	global       *bucket
+	paths        map[ApiPath]*bucket
+	blockedUntil time.Time
+}
+
+func NewRateLimiter(global RateLimit, perPath map[ApiPath]RateLimit) *RateLimiter {
+	l := &RateLimiter{
+		RespectRetryAfter: true,
+		global:            newBucket(global),
+		paths:             make(map[ApiPath]*bucket, len(perPath)),
+	}
+	for path, limit := range perPath {
+		l.paths[path] = newBucket(limit)
+	}
+	return l
+}
+
+// SetLimit задает или меняет лимит для метода API.
+func (l *RateLimiter) SetLimit(path ApiPath, limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.paths[path] = newBucket(limit)
+}
+
+// Wait блокирует до момента, когда запрос к path разрешен, или до отмены ctx.
+func (l *RateLimiter) Wait(ctx context.Context, path ApiPath) error {
+	l.mu.Lock()
+	until := l.blockedUntil
+	b := l.paths[path]
+	l.mu.Unlock()
+
+	if d := time.Until(until); d > 0 {
+		if err := sleepCtx(ctx, d); err != nil {
+			return err
+		}
+	}
+	if err := b.wait(ctx); err != nil {
+		return err
+	}
+	return l.global.wait(ctx)
+}
+
+// Pause приостанавливает все запросы на d.
+func (l *RateLimiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+type bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(limit RateLimit) *bucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &bucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+func (b *bucket) wait(ctx context.Context) error {
+	if b == nil {
+		return ctx.Err()
+	}
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		d := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+		if err := sleepCtx(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// parseRetryAfter разбирает заголовок Retry-After в секундах или в формате HTTP даты.
+func parseRetryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(v); err == nil && sec > 0 {
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -2,7 +2,9 @@ package tehnomir
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -14,28 +16,62 @@ import (
 )
 
 type Client struct {
-	cfg    *Config
-	client *http.Client
-}
-
-func New(cfg *Config) *Client {
-	return &Client{
-		cfg: cfg,
-		client: &http.Client{
-			Timeout: 5 * time.Second,
-			Transport: &http.Transport{
-				DialContext:           (&net.Dialer{Timeout: time.Second}).DialContext,
-				TLSHandshakeTimeout:   time.Second,
-				ResponseHeaderTimeout: time.Second * 3,
-				MaxIdleConns:          100,
-				MaxIdleConnsPerHost:   100,
-				IdleConnTimeout:       60 * time.Second,
-			},
+	cfg       *Config
+	client    *http.Client
+	transport http.RoundTripper
+	retry     RetryPolicy
+	limiter   *RateLimiter
+	cache     *referenceCache
+}
+
+func New(cfg *Config, opts ...Option) *Client {
+	c := &Client{
+		cfg:   cfg,
+		retry: DefaultRetryPolicy(),
+		cache: &referenceCache{
+			store: NewMemoryCache(CACHE_CAPACITY, CACHE_TTL+CACHE_MAX_STALE),
+			cfg:   DefaultCacheConfig(),
 		},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.client == nil {
+		if c.transport == nil {
+			c.transport = newTransport(cfg)
+		}
+		c.client = &http.Client{
+			Timeout:   durationOrDefault(cfg.Timeout, TIMEOUT),
+			Transport: c.transport,
+		}
+	}
+	return c
+}
+
+func newTransport(cfg *Config) *http.Transport {
+	maxIdle := cfg.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = MAX_IDLE_CONNS
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: durationOrDefault(cfg.DialTimeout, DIAL_TIMEOUT)}).DialContext,
+		TLSHandshakeTimeout:   durationOrDefault(cfg.TLSHandshakeTimeout, TLS_HANDSHAKE_TIMEOUT),
+		ResponseHeaderTimeout: durationOrDefault(cfg.ResponseHeaderTimeout, RESPONSE_HEADER_TIMEOUT),
+		MaxIdleConns:          maxIdle,
+		MaxIdleConnsPerHost:   maxIdle,
+		IdleConnTimeout:       durationOrDefault(cfg.IdleConnTimeout, IDLE_CONN_TIMEOUT),
+	}
+}
+
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
 }
 
-func (c *Client) newRequest(path apiPath, body ...any) (*http.Response, error) {
+func (c *Client) newRequest(ctx context.Context, path ApiPath, body ...any) (*http.Response, error) {
 	var reqbody any
 	if body == nil {
 		reqbody = TokenRequestBody{
@@ -49,7 +85,8 @@ func (c *Client) newRequest(path apiPath, body ...any) (*http.Response, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		c.makeApiPath(path),
 		buff)
@@ -62,12 +99,19 @@ func (c *Client) newRequest(path apiPath, body ...any) (*http.Response, error) {
 		return nil, err
 	}
 	if res.StatusCode != http.StatusOK {
-		return nil, makeError(res.Body)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		apiErr := makeError(path, res.StatusCode, body)
+		apiErr.RetryAfter = parseRetryAfter(res.Header)
+		return nil, apiErr
 	}
 	return res, nil
 }
 
-func (c *Client) makeApiPath(path apiPath) string {
+func (c *Client) makeApiPath(path ApiPath) string {
 	u := url.URL{
 		Scheme: c.cfg.Proto,
 		Host:   c.cfg.Host,
@@ -76,7 +120,7 @@ func (c *Client) makeApiPath(path apiPath) string {
 	return u.String()
 }
 
-func (c *Client) makeRequestBody(path apiPath, body any) any {
+func (c *Client) makeRequestBody(path ApiPath, body any) any {
 	switch path {
 	case TestConnect:
 		b, ok := body.(*TestRequestBody)
@@ -147,44 +191,120 @@ func (c *Client) makeRequestBody(path apiPath, body any) any {
 	return body
 }
 
-func (c *Client) requestAndDecode(request apiPath, response any, requestbody ...any) error {
+func (c *Client) requestAndDecode(ctx context.Context, request ApiPath, response any, requestbody ...any) error {
+	attempts := c.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	started := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := c.doRequestAndDecode(ctx, request, response, requestbody...)
+		var retryAfter time.Duration
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			retryAfter = apiErr.RetryAfter
+			if c.limiter != nil && c.limiter.RespectRetryAfter {
+				c.limiter.Pause(retryAfter)
+			}
+		}
+		if err == nil || attempt >= attempts || !c.retry.retryable(err) {
+			return err
+		}
+		if !isIdempotent(request) {
+			applied, cerr := c.mutationApplied(ctx, request, started, response, requestbody...)
+			if cerr != nil {
+				return err
+			}
+			if applied {
+				return nil
+			}
+		}
+		delay := c.retry.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		if err := sleepCtx(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) doRequestAndDecode(ctx context.Context, request ApiPath, response any, requestbody ...any) error {
 	var (
 		body any
 		err  error
 		resp *http.Response
 	)
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx, request); err != nil {
+			return err
+		}
+	}
 	if requestbody != nil {
 		body = requestbody[0]
 		resp, err = c.newRequest(
+			ctx,
 			request,
 			body,
 		)
 	} else {
-		resp, err = c.newRequest(request)
+		resp, err = c.newRequest(ctx, request)
 	}
 
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
-	return json.NewDecoder(resp.Body).Decode(response)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := checkSuccess(request, resp.StatusCode, data); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, response)
 }
 
-func makeError(body io.ReadCloser) error {
-	var resp ResponseError
-	if err := json.NewDecoder(body).Decode(&resp); err != nil {
-		return err
+// checkSuccess проверяет флаг success и тело в формате ResponseError
+// для ответов, пришедших с HTTP 200.
+func checkSuccess(path ApiPath, httpStatus int, data []byte) error {
+	var probe struct {
+		Success *bool `json:"success"`
+		Data    struct {
+			Name    string `json:"name"`
+			Message string `json:"message"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		// не объект data (например массив) - это нормальный ответ
+		var short struct {
+			Success *bool `json:"success"`
+		}
+		if err := json.Unmarshal(data, &short); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrBadResponse, path, err)
+		}
+		probe.Success = short.Success
+	}
+	if probe.Success != nil && !*probe.Success {
+		return makeError(path, httpStatus, data)
 	}
-	return fmt.Errorf("%d:%s - %s", resp.Data.Status, resp.Data.Name, resp.Data.Message)
+	if probe.Success == nil && probe.Data.Name != "" && probe.Data.Message != "" {
+		return makeError(path, httpStatus, data)
+	}
+	return nil
 }
 
 func (c *Client) TestConnect(s ...string) error {
+	return c.TestConnectCtx(context.Background(), s...)
+}
+
+func (c *Client) TestConnectCtx(ctx context.Context, s ...string) error {
 	var phrase string
 	if s != nil {
 		phrase = s[0]
 	}
 	var res TestConnectResponse
-	if err := c.requestAndDecode(TestConnect, &res, &TestRequestBody{Phrase: phrase}); err != nil {
+	if err := c.requestAndDecode(ctx, TestConnect, &res, &TestRequestBody{Phrase: phrase}); err != nil {
 		return err
 	}
 	if s != nil && res.Data.TestString != s[0] {
@@ -193,9 +313,9 @@ func (c *Client) TestConnect(s ...string) error {
 	return nil
 }
 
-func (c *Client) priceSearch(code string, currency Currency, brand int, analog bool) (*PriceSearchResponse, error) {
+func (c *Client) priceSearch(ctx context.Context, code string, currency Currency, brand int, analog bool) (*PriceSearchResponse, error) {
 	var res PriceSearchResponse
-	if err := c.requestAndDecode(PriceSearch, &res,
+	if err := c.requestAndDecode(ctx, PriceSearch, &res,
 		&PriceSearchRequestBody{
 			BrandID:     brand,
 			Code:        utilits.ClearString(code),
@@ -208,40 +328,60 @@ func (c *Client) priceSearch(code string, currency Currency, brand int, analog b
 }
 
 func (c *Client) SearchWithAnalogs(code string, currency ...Currency) (*PriceSearchResponse, error) {
+	return c.SearchWithAnalogsCtx(context.Background(), code, currency...)
+}
+
+func (c *Client) SearchWithAnalogsCtx(ctx context.Context, code string, currency ...Currency) (*PriceSearchResponse, error) {
 	cur := USD
 	if currency != nil {
 		cur = currency[0]
 	}
-	return c.priceSearch(code, cur, 0, true)
+	return c.priceSearch(ctx, code, cur, 0, true)
 }
 
 func (c *Client) SearchByBrandWithAnalogs(code string, brand int, currency ...Currency) (*PriceSearchResponse, error) {
+	return c.SearchByBrandWithAnalogsCtx(context.Background(), code, brand, currency...)
+}
+
+func (c *Client) SearchByBrandWithAnalogsCtx(ctx context.Context, code string, brand int, currency ...Currency) (*PriceSearchResponse, error) {
 	cur := USD
 	if currency != nil {
 		cur = currency[0]
 	}
-	return c.priceSearch(code, cur, brand, true)
+	return c.priceSearch(ctx, code, cur, brand, true)
 }
 
 func (c *Client) SearchByBrandWithoutAnalogs(code string, brand int, currency ...Currency) (*PriceSearchResponse, error) {
+	return c.SearchByBrandWithoutAnalogsCtx(context.Background(), code, brand, currency...)
+}
+
+func (c *Client) SearchByBrandWithoutAnalogsCtx(ctx context.Context, code string, brand int, currency ...Currency) (*PriceSearchResponse, error) {
 	cur := USD
 	if currency != nil {
 		cur = currency[0]
 	}
-	return c.priceSearch(code, cur, brand, false)
+	return c.priceSearch(ctx, code, cur, brand, false)
 }
 
 func (c *Client) GetSuppliers() (*SuppliersResponse, error) {
+	return c.GetSuppliersCtx(context.Background())
+}
+
+func (c *Client) GetSuppliersCtx(ctx context.Context) (*SuppliersResponse, error) {
 	var res SuppliersResponse
-	if err := c.requestAndDecode(GetSuppliers, &res); err != nil {
+	if err := c.cachedRequestAndDecode(ctx, GetSuppliers, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *Client) GetBrands() (*BrandsResponse, error) {
+	return c.GetBrandsCtx(context.Background())
+}
+
+func (c *Client) GetBrandsCtx(ctx context.Context) (*BrandsResponse, error) {
 	var res BrandsResponse
-	if err := c.requestAndDecode(GetBrands, &res); err != nil {
+	if err := c.cachedRequestAndDecode(ctx, GetBrands, &res); err != nil {
 		return nil, err
 	}
 
@@ -249,16 +389,24 @@ func (c *Client) GetBrands() (*BrandsResponse, error) {
 }
 
 func (c *Client) GetBrandGroups() (*BrandGroupsResponse, error) {
+	return c.GetBrandGroupsCtx(context.Background())
+}
+
+func (c *Client) GetBrandGroupsCtx(ctx context.Context) (*BrandGroupsResponse, error) {
 	var res BrandGroupsResponse
-	if err := c.requestAndDecode(GetBrandGroups, &res); err != nil {
+	if err := c.cachedRequestAndDecode(ctx, GetBrandGroups, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *Client) GetProductInfo(code string, brand int) (*ProductInfoResponse, error) {
+	return c.GetProductInfoCtx(context.Background(), code, brand)
+}
+
+func (c *Client) GetProductInfoCtx(ctx context.Context, code string, brand int) (*ProductInfoResponse, error) {
 	var res ProductInfoResponse
-	if err := c.requestAndDecode(GetProductInfo, &res, &ProductInfoRequestBody{
+	if err := c.requestAndDecode(ctx, GetProductInfo, &res, &ProductInfoRequestBody{
 		BrandID: brand,
 		Code:    code,
 	}); err != nil {
@@ -268,9 +416,13 @@ func (c *Client) GetProductInfo(code string, brand int) (*ProductInfoResponse, e
 }
 
 func (c *Client) GetUnloads(from, to time.Time) (*UnloadsResponse, error) {
+	return c.GetUnloadsCtx(context.Background(), from, to)
+}
+
+func (c *Client) GetUnloadsCtx(ctx context.Context, from, to time.Time) (*UnloadsResponse, error) {
 	timeFormat := "2006-01-02"
 	var res UnloadsResponse
-	if err := c.requestAndDecode(GetUnloads, &res, &GetUnloadsRequestBody{
+	if err := c.requestAndDecode(ctx, GetUnloads, &res, &GetUnloadsRequestBody{
 		FromDate: from.Format(timeFormat),
 		ToDate:   to.Format(timeFormat),
 	}); err != nil {
@@ -280,8 +432,12 @@ func (c *Client) GetUnloads(from, to time.Time) (*UnloadsResponse, error) {
 }
 
 func (c *Client) GetUnloadData(unloadID int) (*UnloadResponse, error) {
+	return c.GetUnloadDataCtx(context.Background(), unloadID)
+}
+
+func (c *Client) GetUnloadDataCtx(ctx context.Context, unloadID int) (*UnloadResponse, error) {
 	var res UnloadResponse
-	if err := c.requestAndDecode(GetUnloadData, &res,
+	if err := c.requestAndDecode(ctx, GetUnloadData, &res,
 		&GetUnloadDataRequestBody{
 			UnloadID: unloadID,
 		}); err != nil {
@@ -291,20 +447,28 @@ func (c *Client) GetUnloadData(unloadID int) (*UnloadResponse, error) {
 }
 
 func (c *Client) GetBoxesReady() (*BoxesReadyToSendResponse, error) {
+	return c.GetBoxesReadyCtx(context.Background())
+}
+
+func (c *Client) GetBoxesReadyCtx(ctx context.Context) (*BoxesReadyToSendResponse, error) {
 	var res BoxesReadyToSendResponse
-	if err := c.requestAndDecode(GetBoxesReady, &res); err != nil {
+	if err := c.requestAndDecode(ctx, GetBoxesReady, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *Client) BasketAdd(prodid int64, priceLogo string, quantity int, reference string, comment ...string) (*BasketAddResponse, error) {
+	return c.BasketAddCtx(context.Background(), prodid, priceLogo, quantity, reference, comment...)
+}
+
+func (c *Client) BasketAddCtx(ctx context.Context, prodid int64, priceLogo string, quantity int, reference string, comment ...string) (*BasketAddResponse, error) {
 	var com string
 	if comment != nil {
 		com = comment[0]
 	}
 	var res BasketAddResponse
-	if err := c.requestAndDecode(BasketAdd, &res,
+	if err := c.requestAndDecode(ctx, BasketAdd, &res,
 		&BasketAddRequestBody{
 			ProductID: prodid,
 			PriceLogo: priceLogo,
@@ -318,8 +482,12 @@ func (c *Client) BasketAdd(prodid int64, priceLogo string, quantity int, referen
 }
 
 func (c *Client) GetPositionInfo(reference string) (*PositionsInfoResponse, error) {
+	return c.GetPositionInfoCtx(context.Background(), reference)
+}
+
+func (c *Client) GetPositionInfoCtx(ctx context.Context, reference string) (*PositionsInfoResponse, error) {
 	var res PositionsInfoResponse
-	if err := c.requestAndDecode(GetPositionInfo, &res,
+	if err := c.requestAndDecode(ctx, GetPositionInfo, &res,
 		&PositionInfoRequestBody{
 			Reference: reference,
 		}); err != nil {
@@ -329,16 +497,24 @@ func (c *Client) GetPositionInfo(reference string) (*PositionsInfoResponse, erro
 }
 
 func (c *Client) GetBasketPositions() (*BasketPositionsResponse, error) {
+	return c.GetBasketPositionsCtx(context.Background())
+}
+
+func (c *Client) GetBasketPositionsCtx(ctx context.Context) (*BasketPositionsResponse, error) {
 	var res BasketPositionsResponse
-	if err := c.requestAndDecode(GetBasketPositions, &res); err != nil {
+	if err := c.requestAndDecode(ctx, GetBasketPositions, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *Client) BasketDeletePosition(basketid int) error {
+	return c.BasketDeletePositionCtx(context.Background(), basketid)
+}
+
+func (c *Client) BasketDeletePositionCtx(ctx context.Context, basketid int) error {
 	var res SuccessResponse
-	if err := c.requestAndDecode(BasketDeletePosition, &res,
+	if err := c.requestAndDecode(ctx, BasketDeletePosition, &res,
 		&BasketDeletePositionRequestBody{
 			BasketID: basketid,
 		}); err != nil {
@@ -351,8 +527,12 @@ func (c *Client) BasketDeletePosition(basketid int) error {
 }
 
 func (c *Client) BasketClear() error {
+	return c.BasketClearCtx(context.Background())
+}
+
+func (c *Client) BasketClearCtx(ctx context.Context) error {
 	var res SuccessResponse
-	if err := c.requestAndDecode(BasketClear, &res); err != nil {
+	if err := c.requestAndDecode(ctx, BasketClear, &res); err != nil {
 		return err
 	}
 	if !res.Success {
@@ -362,16 +542,24 @@ func (c *Client) BasketClear() error {
 }
 
 func (c *Client) GetCurrencies() (*CurrenciesResponse, error) {
+	return c.GetCurrenciesCtx(context.Background())
+}
+
+func (c *Client) GetCurrenciesCtx(ctx context.Context) (*CurrenciesResponse, error) {
 	var res CurrenciesResponse
-	if err := c.requestAndDecode(GetCurrencies, &res); err != nil {
+	if err := c.cachedRequestAndDecode(ctx, GetCurrencies, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *Client) BrandsByCode(code string) (*BrandsByCodeResponse, error) {
+	return c.BrandsByCodeCtx(context.Background(), code)
+}
+
+func (c *Client) BrandsByCodeCtx(ctx context.Context, code string) (*BrandsByCodeResponse, error) {
 	var res BrandsByCodeResponse
-	if err := c.requestAndDecode(GetBrandsByCode, &res,
+	if err := c.requestAndDecode(ctx, GetBrandsByCode, &res,
 		&BrandsByCodeRequestBody{
 			Code: code,
 		}); err != nil {
@@ -381,16 +569,24 @@ func (c *Client) BrandsByCode(code string) (*BrandsByCodeResponse, error) {
 }
 
 func (c *Client) PositionStatuses() (*PositionStatusesResponse, error) {
+	return c.PositionStatusesCtx(context.Background())
+}
+
+func (c *Client) PositionStatusesCtx(ctx context.Context) (*PositionStatusesResponse, error) {
 	var res PositionStatusesResponse
-	if err := c.requestAndDecode(GetPositionStatuses, &res); err != nil {
+	if err := c.cachedRequestAndDecode(ctx, GetPositionStatuses, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *Client) OrderCreate(ordernum string) (*OrderResponse, error) {
+	return c.OrderCreateCtx(context.Background(), ordernum)
+}
+
+func (c *Client) OrderCreateCtx(ctx context.Context, ordernum string) (*OrderResponse, error) {
 	var res OrderResponse
-	if err := c.requestAndDecode(OrderCreate, &res,
+	if err := c.requestAndDecode(ctx, OrderCreate, &res,
 		&OrderCreateRequestBody{
 			OrderNumber: ordernum,
 		}); err != nil {
@@ -400,17 +596,25 @@ func (c *Client) OrderCreate(ordernum string) (*OrderResponse, error) {
 }
 
 func (c *Client) ActiveOrders() (*OrdersResponse, error) {
+	return c.ActiveOrdersCtx(context.Background())
+}
+
+func (c *Client) ActiveOrdersCtx(ctx context.Context) (*OrdersResponse, error) {
 	var res OrdersResponse
-	if err := c.requestAndDecode(GetActiveOrders, &res); err != nil {
+	if err := c.requestAndDecode(ctx, GetActiveOrders, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *Client) OrderSearchByDate(fromDate, toDate time.Time) (*OrdersResponse, error) {
+	return c.OrderSearchByDateCtx(context.Background(), fromDate, toDate)
+}
+
+func (c *Client) OrderSearchByDateCtx(ctx context.Context, fromDate, toDate time.Time) (*OrdersResponse, error) {
 	timeFormat := "2006-01-02"
 	var res OrdersResponse
-	if err := c.requestAndDecode(OrderSearch, &res,
+	if err := c.requestAndDecode(ctx, OrderSearch, &res,
 		&OrderSearchRequestBody{
 			FromDate: fromDate.Format(timeFormat),
 			ToDate:   toDate.Format(timeFormat),
@@ -421,8 +625,12 @@ func (c *Client) OrderSearchByDate(fromDate, toDate time.Time) (*OrdersResponse,
 }
 
 func (c *Client) OrderSearchByNumber(ordernum string) (*OrdersResponse, error) {
+	return c.OrderSearchByNumberCtx(context.Background(), ordernum)
+}
+
+func (c *Client) OrderSearchByNumberCtx(ctx context.Context, ordernum string) (*OrdersResponse, error) {
 	var res OrdersResponse
-	if err := c.requestAndDecode(OrderSearch, &res,
+	if err := c.requestAndDecode(ctx, OrderSearch, &res,
 		&OrderSearchRequestBody{
 			OrderNum: ordernum,
 		}); err != nil {
@@ -432,9 +640,13 @@ func (c *Client) OrderSearchByNumber(ordernum string) (*OrdersResponse, error) {
 }
 
 func (c *Client) ChangedPositions(fromDateTime time.Time) (*PositionsInfoResponse, error) {
+	return c.ChangedPositionsCtx(context.Background(), fromDateTime)
+}
+
+func (c *Client) ChangedPositionsCtx(ctx context.Context, fromDateTime time.Time) (*PositionsInfoResponse, error) {
 	timeFormat := "2006-01-02 15:04:05"
 	var res PositionsInfoResponse
-	if err := c.requestAndDecode(GetChangedPositions, &res,
+	if err := c.requestAndDecode(ctx, GetChangedPositions, &res,
 		&GetChangedPositionsRequestBody{
 			FromDate: fromDateTime.Format(timeFormat),
 		}); err != nil {
@@ -444,8 +656,12 @@ func (c *Client) ChangedPositions(fromDateTime time.Time) (*PositionsInfoRespons
 }
 
 func (c *Client) OrderPositions(orderid int) (*PositionsInfoResponse, error) {
+	return c.OrderPositionsCtx(context.Background(), orderid)
+}
+
+func (c *Client) OrderPositionsCtx(ctx context.Context, orderid int) (*PositionsInfoResponse, error) {
 	var res PositionsInfoResponse
-	if err := c.requestAndDecode(GetOrderPositions, &res,
+	if err := c.requestAndDecode(ctx, GetOrderPositions, &res,
 		&GetOrderPositionsRequestBody{
 			OrderID: orderid,
 		}); err != nil {
@@ -456,8 +672,12 @@ func (c *Client) OrderPositions(orderid int) (*PositionsInfoResponse, error) {
 
 // Отличаются статусы: В работе - StatusID: 2 и Закрыт - StatusID: 4
 func (c *Client) OrderPositionsByStatus(statusid int) (*PositionsInfoResponse, error) {
+	return c.OrderPositionsByStatusCtx(context.Background(), statusid)
+}
+
+func (c *Client) OrderPositionsByStatusCtx(ctx context.Context, statusid int) (*PositionsInfoResponse, error) {
 	var res PositionsInfoResponse
-	if err := c.requestAndDecode(GetOrderPositionsByStatus, &res,
+	if err := c.requestAndDecode(ctx, GetOrderPositionsByStatus, &res,
 		&GetOrderPositionsByStatusRequestBody{
 			StatusID: statusid,
 		}); err != nil {
@@ -466,9 +686,22 @@ func (c *Client) OrderPositionsByStatus(statusid int) (*PositionsInfoResponse, e
 	return &res, nil
 }
 
+// OrderPositionsByStatusID то же, что OrderPositionsByStatus, для StatusInWork, StatusClosed и т.д.
+func (c *Client) OrderPositionsByStatusID(status PositionStatusID) (*PositionsInfoResponse, error) {
+	return c.OrderPositionsByStatusIDCtx(context.Background(), status)
+}
+
+func (c *Client) OrderPositionsByStatusIDCtx(ctx context.Context, status PositionStatusID) (*PositionsInfoResponse, error) {
+	return c.OrderPositionsByStatusCtx(ctx, int(status))
+}
+
 func (c *Client) StockPrice() (*StockPriceResponse, error) {
+	return c.StockPriceCtx(context.Background())
+}
+
+func (c *Client) StockPriceCtx(ctx context.Context) (*StockPriceResponse, error) {
 	var res StockPriceResponse
-	if err := c.requestAndDecode(GetStockPrice, &res); err != nil {
+	if err := c.requestAndDecode(ctx, GetStockPrice, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
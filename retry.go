@@ -0,0 +1,184 @@
+package tehnomir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	RETRY_MAX_ATTEMPTS = 3
+	RETRY_BASE_DELAY   = 300 * time.Millisecond
+	RETRY_MAX_DELAY    = 5 * time.Second
+)
+
+// RetryPolicy описывает повтор запросов при сетевых ошибках и временных ответах сервера.
+// Запросы на чтение повторяются всегда, basket/add и order/create - только после того,
+// как проверено, что предыдущая попытка не была выполнена на стороне API.
+type RetryPolicy struct {
+	MaxAttempts   int           // всего попыток, 1 - без повторов
+	BaseDelay     time.Duration // задержка перед первым повтором, дальше удваивается
+	MaxDelay      time.Duration
+	Jitter        bool  // случайная задержка в диапазоне [delay/2, delay]
+	RetryStatuses []int // HTTP статусы, при которых запрос повторяется
+	// RetryErrors решает, повторять ли запрос при сетевой ошибке, nil - TransientError.
+	RetryErrors func(error) bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: RETRY_MAX_ATTEMPTS,
+		BaseDelay:   RETRY_BASE_DELAY,
+		MaxDelay:    RETRY_MAX_DELAY,
+		Jitter:      true,
+		RetryErrors: TransientError,
+		RetryStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// NoRetry отключает повторы.
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		for _, st := range p.RetryStatuses {
+			if apiErr.HTTPStatus == st {
+				return true
+			}
+		}
+		return false
+	}
+	if p.RetryErrors != nil {
+		return p.RetryErrors(err)
+	}
+	return TransientError(err)
+}
+
+// TransientError сетевые ошибки, после которых есть смысл повторить запрос:
+// таймауты, обрыв или отказ соединения, неожиданный конец ответа.
+// Ошибки схемы, адреса и TLS не повторяются.
+func TransientError(err error) bool {
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter && delay > 0 {
+		half := delay / 2
+		delay = half + time.Duration(rand.Int63n(int64(half)+1))
+	}
+	return delay
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func isIdempotent(path ApiPath) bool {
+	switch path {
+	case BasketAdd, OrderCreate, BasketDeletePosition, BasketClear:
+		return false
+	}
+	return true
+}
+
+// mutationApplied проверяет, была ли выполнена неудачная попытка изменяющего запроса.
+// Если запрос уже применен, заполняет response найденными данными.
+// started - время начала первой попытки, заказ созданный раньше считается чужим.
+// Ошибка означает, что проверить нельзя и повторять запрос небезопасно.
+func (c *Client) mutationApplied(ctx context.Context, path ApiPath, started time.Time, response any, requestbody ...any) (bool, error) {
+	if requestbody == nil {
+		return false, ErrBadResponse
+	}
+	switch path {
+	case BasketAdd:
+		b, ok := requestbody[0].(*BasketAddRequestBody)
+		if !ok || b.Reference == "" {
+			return false, ErrBadResponse
+		}
+		basket, err := c.GetBasketPositionsCtx(ctx)
+		if err != nil {
+			return false, err
+		}
+		for _, p := range basket.Positions {
+			if p.HasReference(b.Reference) && p.PriceLogo == b.PriceLogo && p.Quantity == b.Quantity {
+				if res, ok := response.(*BasketAddResponse); ok {
+					res.Success = true
+					res.Data.BasketID = p.BasketID
+				}
+				return true, nil
+			}
+		}
+		return false, nil
+	case OrderCreate:
+		b, ok := requestbody[0].(*OrderCreateRequestBody)
+		if !ok || b.OrderNumber == "" {
+			return false, ErrBadResponse
+		}
+		orders, err := c.OrderSearchByNumberCtx(ctx, b.OrderNumber)
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		// CreateTime приходит в часах API с точностью до секунды
+		since := apiWallClock(started, nil).Truncate(time.Second)
+		for _, o := range orders.Orders {
+			if o.OrderNumber != b.OrderNumber {
+				continue
+			}
+			created := time.Time(o.CreateTime)
+			if created.IsZero() || created.Before(since) {
+				// номер уже занят другим заказом, повтор создаст дубль или вернет чужой заказ
+				return false, fmt.Errorf("%w: order number %s already used", ErrBadResponse, b.OrderNumber)
+			}
+			if res, ok := response.(*OrderResponse); ok {
+				res.Success = true
+				res.Order = o
+			}
+			return true, nil
+		}
+		return false, nil
+	}
+	return false, ErrBadResponse
+}
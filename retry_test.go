@@ -0,0 +1,50 @@
+package tehnomir
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestTransientError(t *testing.T) {
+	_, schemeErr := http.Get("foo://example")
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"timeout", &url.Error{Op: "Post", URL: "u", Err: timeoutError{}}, true},
+		{"reset", &url.Error{Op: "Post", URL: "u", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"refused", &url.Error{Op: "Post", URL: "u", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, true},
+		{"unexpected eof", fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), true},
+		{"bad scheme", schemeErr, false},
+		{"plain", errors.New("x509: certificate signed by unknown authority"), false},
+	}
+	for _, tt := range tests {
+		if got := TransientError(tt.err); got != tt.want {
+			t.Errorf("%s: TransientError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyRetryErrors(t *testing.T) {
+	p := DefaultRetryPolicy()
+	p.RetryErrors = func(err error) bool { return errors.Is(err, io.EOF) }
+	if !p.retryable(io.EOF) {
+		t.Error("custom RetryErrors ignored")
+	}
+	if p.retryable(&url.Error{Op: "Post", URL: "u", Err: timeoutError{}}) {
+		t.Error("custom RetryErrors should replace TransientError")
+	}
+}
@@ -0,0 +1,328 @@
+package tehnomir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PositionStatusID идентификатор статуса позиции заказа, полный список - PositionStatuses.
+type PositionStatusID int
+
+const (
+	StatusInWork PositionStatusID = 2
+	StatusClosed PositionStatusID = 4
+)
+
+// StatusStage этап жизненного цикла позиции, по нему проверяются переходы.
+type StatusStage int
+
+const (
+	StageUnknown StatusStage = iota
+	StageNew
+	StageInWork
+	StageInTransit
+	StageArrived
+	StageClosed
+	StageRefused
+)
+
+func (s StatusStage) String() string {
+	switch s {
+	case StageNew:
+		return "new"
+	case StageInWork:
+		return "in_work"
+	case StageInTransit:
+		return "in_transit"
+	case StageArrived:
+		return "arrived"
+	case StageClosed:
+		return "closed"
+	case StageRefused:
+		return "refused"
+	}
+	return "unknown"
+}
+
+// IsFinal true для этапов, после которых статус не меняется.
+func (s StatusStage) IsFinal() bool {
+	return s == StageClosed || s == StageRefused
+}
+
+var knownStages = map[PositionStatusID]StatusStage{
+	StatusInWork: StageInWork,
+	StatusClosed: StageClosed,
+}
+
+// ключевые слова в названии и описании статуса, порядок проверки важен
+var stageKeywords = []struct {
+	stage    StatusStage
+	keywords []string
+}{
+	{StageRefused, []string{"отказ", "відмов", "отмен", "скасов", "refus", "cancel"}},
+	{StageClosed, []string{"закрыт", "закрит", "выдан", "видан", "closed", "issued", "delivered"}},
+	{StageArrived, []string{"на склад", "пришл", "прибыл", "прибув", "готов", "arrived", "ready"}},
+	{StageInTransit, []string{"в пути", "в дороз", "отгруж", "відвантаж", "отправ", "відправ", "transit", "shipped"}},
+	{StageInWork, []string{"в работ", "в робот", "заказан", "замовлен", "in work", "ordered", "processing"}},
+	{StageNew, []string{"нов", "принят", "прийнят", "new"}},
+}
+
+// Stage этап статуса: для известных ID - по константам, иначе по названию и описанию.
+func (s PositionStatus) Stage() StatusStage {
+	if st, ok := knownStages[s.StatusID]; ok {
+		return st
+	}
+	text := strings.ToLower(s.Status + " " + s.Description)
+	for _, sk := range stageKeywords {
+		for _, kw := range sk.keywords {
+			if strings.Contains(text, kw) {
+				return sk.stage
+			}
+		}
+	}
+	return StageUnknown
+}
+
+func (s PositionStatus) IsFinal() bool {
+	return s.Stage().IsFinal()
+}
+
+func (s PositionStatus) IsRefused() bool {
+	return s.Stage() == StageRefused
+}
+
+func (s PositionStatus) IsInTransit() bool {
+	return s.Stage() == StageInTransit
+}
+
+// PositionStatusSet справочник статусов из PositionStatuses с этапами.
+// Этап любого статуса можно переопределить через SetStage.
+type PositionStatusSet struct {
+	mu        sync.RWMutex
+	statuses  map[PositionStatusID]PositionStatus
+	overrides map[PositionStatusID]StatusStage
+}
+
+func NewPositionStatusSet(statuses ...PositionStatus) *PositionStatusSet {
+	set := &PositionStatusSet{
+		statuses:  make(map[PositionStatusID]PositionStatus, len(statuses)),
+		overrides: make(map[PositionStatusID]StatusStage),
+	}
+	for _, s := range statuses {
+		set.statuses[s.StatusID] = s
+	}
+	return set
+}
+
+// LoadPositionStatuses загружает справочник статусов из API.
+func (c *Client) LoadPositionStatuses(ctx context.Context) (*PositionStatusSet, error) {
+	res, err := c.PositionStatusesCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewPositionStatusSet(res.Statuses...), nil
+}
+
+func (set *PositionStatusSet) SetStage(id PositionStatusID, stage StatusStage) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	set.overrides[id] = stage
+}
+
+func (set *PositionStatusSet) Lookup(id PositionStatusID) (PositionStatus, bool) {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	s, ok := set.statuses[id]
+	return s, ok
+}
+
+func (set *PositionStatusSet) Stage(id PositionStatusID) StatusStage {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	if st, ok := set.overrides[id]; ok {
+		return st
+	}
+	if s, ok := set.statuses[id]; ok {
+		return s.Stage()
+	}
+	return knownStages[id]
+}
+
+// Statuses все статусы, отсортированные по ID.
+func (set *PositionStatusSet) Statuses() []PositionStatus {
+	set.mu.RLock()
+	statuses := make([]PositionStatus, 0, len(set.statuses))
+	for _, s := range set.statuses {
+		statuses = append(statuses, s)
+	}
+	set.mu.RUnlock()
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].StatusID < statuses[j].StatusID
+	})
+	return statuses
+}
+
+func (set *PositionStatusSet) IsFinal(id PositionStatusID) bool {
+	return set.Stage(id).IsFinal()
+}
+
+func (set *PositionStatusSet) IsRefused(id PositionStatusID) bool {
+	return set.Stage(id) == StageRefused
+}
+
+func (set *PositionStatusSet) IsInTransit(id PositionStatusID) bool {
+	return set.Stage(id) == StageInTransit
+}
+
+var (
+	ErrStatusUnknown    = errors.New("unknown position status")
+	ErrStatusRegression = errors.New("position status went back")
+	ErrStatusAfterFinal = errors.New("position status changed after final")
+)
+
+// TransitionError недопустимый или подозрительный переход статуса.
+type TransitionError struct {
+	Reason    error // одна из ErrStatus*
+	From, To  PositionStatusID
+	FromStage StatusStage
+	ToStage   StatusStage
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%v: %d (%s) -> %d (%s)", e.Reason, e.From, e.FromStage, e.To, e.ToStage)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return e.Reason
+}
+
+// StatusMachine проверяет переходы статусов позиций: этап не должен уменьшаться,
+// из финальных этапов переходов нет, отказ возможен с любого нефинального этапа.
+// Исключения задаются через Allow.
+type StatusMachine struct {
+	statuses *PositionStatusSet
+
+	mu      sync.RWMutex
+	allowed map[[2]PositionStatusID]bool
+}
+
+func NewStatusMachine(statuses *PositionStatusSet) *StatusMachine {
+	if statuses == nil {
+		statuses = NewPositionStatusSet()
+	}
+	return &StatusMachine{
+		statuses: statuses,
+		allowed:  make(map[[2]PositionStatusID]bool),
+	}
+}
+
+// Allow разрешает переход from -> to независимо от этапов.
+func (m *StatusMachine) Allow(from, to PositionStatusID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.allowed[[2]PositionStatusID{from, to}] = true
+}
+
+// Transition проверяет переход from -> to, nil - переход допустим.
+func (m *StatusMachine) Transition(from, to PositionStatusID) error {
+	if from == to {
+		return nil
+	}
+	m.mu.RLock()
+	allowed := m.allowed[[2]PositionStatusID{from, to}]
+	m.mu.RUnlock()
+	if allowed {
+		return nil
+	}
+	te := &TransitionError{
+		From:      from,
+		To:        to,
+		FromStage: m.statuses.Stage(from),
+		ToStage:   m.statuses.Stage(to),
+	}
+	switch {
+	case te.FromStage == StageUnknown || te.ToStage == StageUnknown:
+		te.Reason = ErrStatusUnknown
+	case te.FromStage.IsFinal():
+		te.Reason = ErrStatusAfterFinal
+	case te.ToStage == StageRefused:
+		return nil
+	case te.ToStage < te.FromStage:
+		te.Reason = ErrStatusRegression
+	default:
+		return nil
+	}
+	return te
+}
+
+// StatusAnomaly подозрительный переход в истории позиции.
+type StatusAnomaly struct {
+	OrderPositionID int
+	At              time.Time
+	Err             *TransitionError
+}
+
+// Anomalies проверяет историю статусов позиции. Строки отказа по части количества
+// идут параллельно основной строке и в цепочку переходов не включаются.
+func (m *StatusMachine) Anomalies(p Position) []StatusAnomaly {
+	states := make([]StatePosition, 0, len(p.States))
+	for _, s := range p.States {
+		if len(p.States) > 1 && m.statuses.Stage(s.StatusID) == StageRefused {
+			continue
+		}
+		states = append(states, s)
+	}
+	sort.SliceStable(states, func(i, j int) bool {
+		return time.Time(states[i].StatusChangedDate).Before(time.Time(states[j].StatusChangedDate))
+	})
+	var anomalies []StatusAnomaly
+	for i := 1; i < len(states); i++ {
+		err := m.Transition(states[i-1].StatusID, states[i].StatusID)
+		var te *TransitionError
+		if errors.As(err, &te) {
+			anomalies = append(anomalies, StatusAnomaly{
+				OrderPositionID: p.OrderPositionID,
+				At:              time.Time(states[i].StatusChangedDate),
+				Err:             te,
+			})
+		}
+	}
+	return anomalies
+}
+
+// StatusTracker запоминает последний статус каждой позиции и проверяет новые события
+// из Watcher по StatusMachine.
+type StatusTracker struct {
+	machine *StatusMachine
+
+	mu   sync.Mutex
+	last map[int]PositionStatusID
+}
+
+func NewStatusTracker(machine *StatusMachine) *StatusTracker {
+	return &StatusTracker{
+		machine: machine,
+		last:    make(map[int]PositionStatusID),
+	}
+}
+
+// Observe запоминает статус события и возвращает ошибку перехода, если он подозрителен.
+// Отказ по части количества не меняет запомненный статус позиции.
+func (t *StatusTracker) Observe(e PositionChanged) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prev, ok := t.last[e.OrderPositionID]
+	refused := t.machine.statuses.Stage(e.StatusID) == StageRefused
+	if !refused || len(e.Position.States) <= 1 {
+		t.last[e.OrderPositionID] = e.StatusID
+	}
+	if !ok {
+		return nil
+	}
+	return t.machine.Transition(prev, e.StatusID)
+}
@@ -14,35 +14,35 @@ const (
 	USD Currency = "USD"
 	UAH Currency = "UAH"
 
-	TestConnect apiPath = "test/connect"
-
-	PriceSearch   apiPath = "price/search"
-	GetStockPrice apiPath = "price/getStockPrice"
-
-	GetUnloads    apiPath = "unload/search"
-	GetUnloadData apiPath = "unload/getData"
-	GetBoxesReady apiPath = "unload/getBoxesReadyToSend"
-
-	GetSuppliers        apiPath = "info/getSuppliers"
-	GetBrands           apiPath = "info/getBrands"
-	GetBrandGroups      apiPath = "info/getBrandGroups"
-	GetProductInfo      apiPath = "info/getProductInfo"
-	GetCurrencies       apiPath = "info/getCurrencies"
-	GetBrandsByCode     apiPath = "info/getBrandsByCode"
-	GetPositionStatuses apiPath = "info/getPositionStatuses"
-
-	BasketAdd            apiPath = "basket/add"
-	GetBasketPositions   apiPath = "basket/getPositions"
-	BasketDeletePosition apiPath = "basket/delete"
-	BasketClear          apiPath = "basket/clear"
-
-	GetPositionInfo           apiPath = "order/getPositionInfo"
-	OrderCreate               apiPath = "order/create"
-	GetActiveOrders           apiPath = "order/getActive"
-	OrderSearch               apiPath = "order/search"
-	GetChangedPositions       apiPath = "order/getChangedPositions"
-	GetOrderPositions         apiPath = "order/getOrderPositions"
-	GetOrderPositionsByStatus apiPath = "order/getOrderPositionsByStatus"
+	TestConnect ApiPath = "test/connect"
+
+	PriceSearch   ApiPath = "price/search"
+	GetStockPrice ApiPath = "price/getStockPrice"
+
+	GetUnloads    ApiPath = "unload/search"
+	GetUnloadData ApiPath = "unload/getData"
+	GetBoxesReady ApiPath = "unload/getBoxesReadyToSend"
+
+	GetSuppliers        ApiPath = "info/getSuppliers"
+	GetBrands           ApiPath = "info/getBrands"
+	GetBrandGroups      ApiPath = "info/getBrandGroups"
+	GetProductInfo      ApiPath = "info/getProductInfo"
+	GetCurrencies       ApiPath = "info/getCurrencies"
+	GetBrandsByCode     ApiPath = "info/getBrandsByCode"
+	GetPositionStatuses ApiPath = "info/getPositionStatuses"
+
+	BasketAdd            ApiPath = "basket/add"
+	GetBasketPositions   ApiPath = "basket/getPositions"
+	BasketDeletePosition ApiPath = "basket/delete"
+	BasketClear          ApiPath = "basket/clear"
+
+	GetPositionInfo           ApiPath = "order/getPositionInfo"
+	OrderCreate               ApiPath = "order/create"
+	GetActiveOrders           ApiPath = "order/getActive"
+	OrderSearch               ApiPath = "order/search"
+	GetChangedPositions       ApiPath = "order/getChangedPositions"
+	GetOrderPositions         ApiPath = "order/getOrderPositions"
+	GetOrderPositionsByStatus ApiPath = "order/getOrderPositionsByStatus"
 )
 
 const (
@@ -51,31 +51,48 @@ const (
 	PRICE_AVIA   float64 = 9.0
 	PRICE_SEA    float64 = 4.0
 	PRICE_VOLUME float64 = 15.0
+
+	TIMEOUT                 = 30 * time.Second
+	DIAL_TIMEOUT            = 5 * time.Second
+	TLS_HANDSHAKE_TIMEOUT   = 5 * time.Second
+	RESPONSE_HEADER_TIMEOUT = 25 * time.Second
+	IDLE_CONN_TIMEOUT       = 60 * time.Second
+	MAX_IDLE_CONNS          = 100
 )
 
 type (
 	Currency string
-	apiPath  string
+	ApiPath  string
 )
 
 type Config struct {
-	Token       string        `cfg:"token"`
-	Proto       string        `cfg:"proto"`
-	Host        string        `cfg:"host"`
-	Timeout     time.Duration `cfg:"timeout"`
-	PriceAvia   float64       `cfg:"price_avia"`
-	PriceSea    float64       `cfg:"price_sea"`
-	PriceVolume float64       `cfg:"price_volume"`
+	Token                 string        `cfg:"token"`
+	Proto                 string        `cfg:"proto"`
+	Host                  string        `cfg:"host"`
+	Timeout               time.Duration `cfg:"timeout"` //общий таймаут запроса, включая чтение тела ответа
+	DialTimeout           time.Duration `cfg:"dial_timeout"`
+	TLSHandshakeTimeout   time.Duration `cfg:"tls_handshake_timeout"`
+	ResponseHeaderTimeout time.Duration `cfg:"response_header_timeout"`
+	IdleConnTimeout       time.Duration `cfg:"idle_conn_timeout"`
+	MaxIdleConns          int           `cfg:"max_idle_conns"`
+	PriceAvia             float64       `cfg:"price_avia"`
+	PriceSea              float64       `cfg:"price_sea"`
+	PriceVolume           float64       `cfg:"price_volume"`
 }
 
 func DefaultConfig() *Config {
 	return &Config{
-		Proto:       PROTO_TM,
-		Host:        URL_API_TM,
-		Timeout:     time.Duration(3 * time.Second),
-		PriceAvia:   PRICE_AVIA,
-		PriceSea:    PRICE_SEA,
-		PriceVolume: PRICE_VOLUME,
+		Proto:                 PROTO_TM,
+		Host:                  URL_API_TM,
+		Timeout:               TIMEOUT,
+		DialTimeout:           DIAL_TIMEOUT,
+		TLSHandshakeTimeout:   TLS_HANDSHAKE_TIMEOUT,
+		ResponseHeaderTimeout: RESPONSE_HEADER_TIMEOUT,
+		IdleConnTimeout:       IDLE_CONN_TIMEOUT,
+		MaxIdleConns:          MAX_IDLE_CONNS,
+		PriceAvia:             PRICE_AVIA,
+		PriceSea:              PRICE_SEA,
+		PriceVolume:           PRICE_VOLUME,
 	}
 }
 
@@ -212,21 +229,21 @@ type FoundDetail struct {
 }
 
 type OfferSupplier struct {
-	PriceLogo       string              `json:"priceLogo"`
-	Price           float64             `json:"price"`
-	Currency        string              `json:"currency"`
-	Quantity        utilits.CustomInt64 `json:"quantity"`
-	QuantityType    string              `json:"quantityType"`
-	Multiplicity    int                 `json:"multiplicity"`
-	PriceQuality    float64             `json:"priceQuality"`
-	DeliveryTypeID  int                 `json:"deliveryTypeId"`
-	DeliveryType    string              `json:"deliveryType"`
-	DeliveryDays    int                 `json:"deliveryTime"`
-	DeliveryDate    utilits.CustomTime  `json:"deliveryDate"`
-	DeliveryPercent int                 `json:"deliveryPercent"`
-	PriceChangeDate utilits.CustomTime  `json:"priceChangeDate"`
-	IsReturn        utilits.CustomBool  `json:"isReturn"`
-	IsPriceFinal    utilits.CustomBool  `json:"isPriceFinal"`
+	PriceLogo       string                `json:"priceLogo"`
+	Price           utilits.CustomFloat64 `json:"price"`
+	Currency        string                `json:"currency"`
+	Quantity        utilits.CustomInt64   `json:"quantity"`
+	QuantityType    string                `json:"quantityType"`
+	Multiplicity    int                   `json:"multiplicity"`
+	PriceQuality    float64               `json:"priceQuality"`
+	DeliveryTypeID  int                   `json:"deliveryTypeId"`
+	DeliveryType    string                `json:"deliveryType"`
+	DeliveryDays    int                   `json:"deliveryTime"`
+	DeliveryDate    utilits.CustomTime    `json:"deliveryDate"`
+	DeliveryPercent int                   `json:"deliveryPercent"`
+	PriceChangeDate utilits.CustomTime    `json:"priceChangeDate"`
+	IsReturn        utilits.CustomBool    `json:"isReturn"`
+	IsPriceFinal    utilits.CustomBool    `json:"isPriceFinal"`
 }
 
 type SuppliersResponse struct {
@@ -280,7 +297,7 @@ type Unload struct {
 	CreateTime     utilits.CustomTime    `json:"createTime"` //"2006-01-02 15:04:05.000000",
 	BoxQuantity    int                   `json:"boxQuantity"`
 	SumPositions   utilits.CustomFloat64 `json:"sumPositions"`
-	SumWorks       int                   `json:"sumWorks"`
+	SumWorks       utilits.CustomFloat64 `json:"sumWorks"`
 	SumDelivery    utilits.CustomFloat64 `json:"sumDelivery"`
 	SumTotal       utilits.CustomFloat64 `json:"sumTotal"`
 	Carrier        string                `json:"carrier"`
@@ -307,25 +324,25 @@ type UnloadBox struct {
 }
 
 type UnloadPosition struct {
-	BoxID           int     `json:"boxId"`
-	OrderID         int     `json:"orderId"`     //номер заказа в ТМ в цифровом типе
-	OrderNumber     string  `json:"orderNumber"` //номер заказа в ТМ в строковом типе
-	OrderPositionID int     `json:"orderPositionId"`
-	PriceLogo       string  `json:"priceLogo"`
-	Brand           string  `json:"brand"`
-	BrandID         int     `json:"brandId"`
-	Code            string  `json:"code"`
-	DescriptionRus  string  `json:"descriptionRus"`
-	DescriptionUa   string  `json:"descriptionUa"`
-	Quantity        int     `json:"quantity"`
-	Price           float64 `json:"price"`
-	PriceFinal      float64 `json:"priceFinal"`
-	Currency        string  `json:"currency"`
-	Reference       string  `json:"reference"` //!отдает в верхнем регистре
-	Comment         string  `json:"comment"`
-	AdminComment    string  `json:"adminComment"`
-	Weight          float64 `json:"weight"`
-	Sticker         string  `json:"sticker"`
+	BoxID           int                   `json:"boxId"`
+	OrderID         int                   `json:"orderId"`     //номер заказа в ТМ в цифровом типе
+	OrderNumber     string                `json:"orderNumber"` //номер заказа в ТМ в строковом типе
+	OrderPositionID int                   `json:"orderPositionId"`
+	PriceLogo       string                `json:"priceLogo"`
+	Brand           string                `json:"brand"`
+	BrandID         int                   `json:"brandId"`
+	Code            string                `json:"code"`
+	DescriptionRus  string                `json:"descriptionRus"`
+	DescriptionUa   string                `json:"descriptionUa"`
+	Quantity        int                   `json:"quantity"`
+	Price           utilits.CustomFloat64 `json:"price"`
+	PriceFinal      utilits.CustomFloat64 `json:"priceFinal"`
+	Currency        string                `json:"currency"`
+	Reference       string                `json:"reference"` //!отдает в верхнем регистре
+	Comment         string                `json:"comment"`
+	AdminComment    string                `json:"adminComment"`
+	Weight          utilits.CustomFloat64 `json:"weight"`
+	Sticker         string                `json:"sticker"`
 }
 
 type BoxesReadyToSendResponse struct {
@@ -335,28 +352,30 @@ type BoxesReadyToSendResponse struct {
 
 type ProductInfoResponse struct {
 	SuccessResponse
-	Data struct {
-		ProductID      int                   `json:"productId"`
-		Brand          string                `json:"brand"`
-		Code           string                `json:"code"`
-		DescriptionRus string                `json:"descriptionRus"`
-		DescriptionUa  string                `json:"descriptionUa"`
-		Weight         float64               `json:"weight"`
-		Volume         utilits.CustomFloat64 `json:"volume"`
-		Images         []struct {
-			Image string `json:"image"`
-		} `json:"images"`
-		Properties []interface{} `json:"properties"`
-		Analogs    []struct {
-			ProductID      int                   `json:"productId"`
-			Brand          string                `json:"brand"`
-			Code           string                `json:"code"`
-			DescriptionRus string                `json:"descriptionRus"`
-			DescriptionUa  string                `json:"descriptionUa"`
-			Weight         float64               `json:"weight"`
-			Volume         utilits.CustomFloat64 `json:"volume"`
-		} `json:"analogs"`
-	} `json:"data"`
+	Data Product `json:"data"`
+}
+
+type Product struct {
+	ProductID      int                   `json:"productId"`
+	Brand          string                `json:"brand"`
+	Code           string                `json:"code"`
+	DescriptionRus string                `json:"descriptionRus"`
+	DescriptionUa  string                `json:"descriptionUa"`
+	Weight         utilits.CustomFloat64 `json:"weight"`
+	Volume         utilits.CustomFloat64 `json:"volume"`
+	Images         []ProductImage        `json:"images"`
+	Properties     []ProductProperty     `json:"properties"`
+	Analogs        []Analog              `json:"analogs"`
+}
+
+type Analog struct {
+	ProductID      int                   `json:"productId"`
+	Brand          string                `json:"brand"`
+	Code           string                `json:"code"`
+	DescriptionRus string                `json:"descriptionRus"`
+	DescriptionUa  string                `json:"descriptionUa"`
+	Weight         utilits.CustomFloat64 `json:"weight"`
+	Volume         utilits.CustomFloat64 `json:"volume"`
 }
 
 type BasketAddResponse struct {
@@ -392,54 +411,62 @@ type Position struct {
 
 type StatePosition struct {
 	Quantity          int                `json:"quantity"`
-	StatusID          int                `json:"statusId"`
+	StatusID          PositionStatusID   `json:"statusId"`
 	Status            string             `json:"status"`
 	StatusChangedDate utilits.CustomTime `json:"statusChangedDate"` //"2006-01-02 15:04:05.000000"
 }
 
 type BasketPositionsResponse struct {
 	SuccessResponse
-	Positions []struct {
-		BasketID  int    `json:"basketId"`
-		PriceLogo string `json:"priceLogo"`
-		BrandID   int    `json:"brandId"`
-		Brand     string `json:"brand"`
-		Code      string `json:"code"`
-		Quantity  int    `json:"quantity"`
-		Price     int    `json:"price"`
-		Currency  string `json:"currency"`
-		Reference string `json:"reference"`
-		Comment   string `json:"comment"`
-	} `json:"data"`
+	Positions []BasketPosition `json:"data"`
+}
+
+type BasketPosition struct {
+	BasketID  int                   `json:"basketId"`
+	PriceLogo string                `json:"priceLogo"`
+	BrandID   int                   `json:"brandId"`
+	Brand     string                `json:"brand"`
+	Code      string                `json:"code"`
+	Quantity  int                   `json:"quantity"`
+	Price     utilits.CustomFloat64 `json:"price"`
+	Currency  string                `json:"currency"`
+	Reference string                `json:"reference"`
+	Comment   string                `json:"comment"`
 }
 
 type CurrenciesResponse struct {
 	SuccessResponse
-	Currencies []struct {
-		Currency string  `json:"currency"`
-		Rate     float64 `json:"rate"`
-	} `json:"data"`
+	Currencies []CurrencyRate `json:"data"`
+}
+
+type CurrencyRate struct {
+	Currency string                `json:"currency"`
+	Rate     utilits.CustomFloat64 `json:"rate"`
 }
 
 type BrandsByCodeResponse struct {
 	SuccessResponse
-	Data []struct {
-		BrandID        int    `json:"brandId"`
-		Brand          string `json:"brand"`
-		DescriptionRus string `json:"descriptionRus"`
-		DescriptionUa  string `json:"descriptionUa"`
-		OffersCount    int    `json:"offersCount"`
-		BrandGroupID   int    `json:"brandGroupId"`
-	} `json:"data"`
+	Data []BrandByCode `json:"data"`
+}
+
+type BrandByCode struct {
+	BrandID        int    `json:"brandId"`
+	Brand          string `json:"brand"`
+	DescriptionRus string `json:"descriptionRus"`
+	DescriptionUa  string `json:"descriptionUa"`
+	OffersCount    int    `json:"offersCount"`
+	BrandGroupID   int    `json:"brandGroupId"`
 }
 
 type PositionStatusesResponse struct {
-	Success  bool `json:"success"`
-	Statuses []struct {
-		StatusID    int    `json:"statusId"`
-		Status      string `json:"status"`
-		Description string `json:"description"`
-	} `json:"data"`
+	Success  bool             `json:"success"`
+	Statuses []PositionStatus `json:"data"`
+}
+
+type PositionStatus struct {
+	StatusID    PositionStatusID `json:"statusId"`
+	Status      string           `json:"status"`
+	Description string           `json:"description"`
 }
 
 type Order struct {
@@ -0,0 +1,173 @@
+package utilits
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// DECIMAL_SCALE количество знаков после запятой, которые хранит Decimal.
+const DECIMAL_SCALE = 6
+
+var decimalUnit = big.NewInt(1_000_000)
+
+// Decimal число с фиксированной точкой (6 знаков после запятой) для денежных сумм и курсов.
+type Decimal struct {
+	units int64
+}
+
+func NewDecimal(value int64, exp int) Decimal {
+	r := new(big.Rat).SetInt64(value)
+	if exp > 0 {
+		r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)))
+	} else if exp < 0 {
+		r.Quo(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-exp)), nil)))
+	}
+	return decimalFromRat(r)
+}
+
+func DecimalFromFloat(f float64) Decimal {
+	return Decimal{units: int64(math.Round(f * 1e6))}
+}
+
+func DecimalFromInt(i int64) Decimal {
+	return Decimal{units: i * 1_000_000}
+}
+
+// ParseDecimal разбирает строку вида "1234.56", "1,234.56" или "-0.5".
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("Decimal: parsing %q: invalid syntax", s)
+	}
+	return decimalFromRat(r), nil
+}
+
+func decimalFromRat(r *big.Rat) Decimal {
+	r = new(big.Rat).Mul(r, new(big.Rat).SetInt(decimalUnit))
+	num, den := r.Num(), r.Denom()
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	// округление половины от нуля
+	if new(big.Int).Mul(new(big.Int).Abs(m), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Decimal{units: q.Int64()}
+}
+
+func (d Decimal) rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(d.units), decimalUnit)
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	return Decimal{units: d.units + o.units}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	return Decimal{units: d.units - o.units}
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return decimalFromRat(new(big.Rat).Mul(d.rat(), o.rat()))
+}
+
+func (d Decimal) MulInt(i int64) Decimal {
+	return Decimal{units: d.units * i}
+}
+
+// Div делит на o, деление на ноль возвращает ноль.
+func (d Decimal) Div(o Decimal) Decimal {
+	if o.units == 0 {
+		return Decimal{}
+	}
+	return decimalFromRat(new(big.Rat).Quo(d.rat(), o.rat()))
+}
+
+// Round округляет до places знаков после запятой, половина - от нуля.
+func (d Decimal) Round(places int) Decimal {
+	if places >= DECIMAL_SCALE {
+		return d
+	}
+	if places < 0 {
+		places = 0
+	}
+	step := int64(math.Pow10(DECIMAL_SCALE - places))
+	q, r := d.units/step, d.units%step
+	if r < 0 {
+		r = -r
+	}
+	if r*2 >= step {
+		if d.units < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return Decimal{units: q * step}
+}
+
+func (d Decimal) Cmp(o Decimal) int {
+	switch {
+	case d.units < o.units:
+		return -1
+	case d.units > o.units:
+		return 1
+	}
+	return 0
+}
+
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+func (d Decimal) Sign() int {
+	return d.Cmp(Decimal{})
+}
+
+func (d Decimal) Float64() float64 {
+	return float64(d.units) / 1e6
+}
+
+func (d Decimal) String() string {
+	s := d.rat().FloatString(DECIMAL_SCALE)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// StringFixed форматирует с places знаками после запятой.
+func (d Decimal) StringFixed(places int) string {
+	return d.rat().FloatString(places)
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Decimal{}
+		return nil
+	}
+	s := string(bytes.Trim(data, `"`))
+	if s == "" || s == "-" {
+		*d = Decimal{}
+		return nil
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return fmt.Errorf("Decimal: UnmarshalJSON data [%s]: %w", string(data), err)
+	}
+	*d = v
+	return nil
+}
+
+// Decimal переводит значение в Decimal.
+func (cf CustomFloat64) Decimal() Decimal {
+	return DecimalFromFloat(cf.Float64)
+}
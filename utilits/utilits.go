@@ -28,7 +28,7 @@ func (cb *CustomBool) UnmarshalJSON(data []byte) error {
 	switch string(data) {
 	case `"TRUE"`, `TRUE`, `"true"`, `true`, `"1"`, `1`:
 		*cb = true
-	case `"FALSE"`, `FALSE`, `"false"`, `false`, `"0"`, `0`, `""`:
+	case `"FALSE"`, `FALSE`, `"false"`, `false`, `"0"`, `0`, `""`, `null`:
 		*cb = false
 	default:
 		return fmt.Errorf(`CustomBool: parsing "%s": unknown value`, string(data))
@@ -37,6 +37,11 @@ func (cb *CustomBool) UnmarshalJSON(data []byte) error {
 }
 
 func (cf *CustomFloat64) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case `null`, `""`, `"-"`:
+		cf.Float64 = 0
+		return nil
+	}
 	if data[0] == QUOTES_BYTE {
 		if err := json.Unmarshal(bytes.Replace(data[1:len(data)-1], []byte{44}, []byte{}, -1), &cf.Float64); err != nil {
 			return fmt.Errorf("CustomFloat64: UnmarshalJSON with quotes data [%s]: %w", string(data[1:len(data)-1]), err)
@@ -49,6 +54,10 @@ func (cf *CustomFloat64) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (cf CustomFloat64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cf.Float64)
+}
+
 func (ci *CustomInt64) UnmarshalJSON(data []byte) error {
 	if data[0] == QUOTES_BYTE {
 		if string(data[1:len(data)-1]) == "-" {
@@ -66,6 +75,10 @@ func (ci *CustomInt64) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (ci CustomInt64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ci.Int64)
+}
+
 func (ci *CustomInt) UnmarshalJSON(data []byte) error {
 	if data[0] == QUOTES_BYTE {
 		if string(data[1:len(data)-1]) == "-" {
@@ -83,7 +96,15 @@ func (ci *CustomInt) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (ci CustomInt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ci.Int)
+}
+
 func (ct *CustomTime) UnmarshalJSON(data []byte) error {
+	if string(data) == `null` {
+		*ct = CustomTime{}
+		return nil
+	}
 	if data[0] == QUOTES_BYTE {
 		if string(data[1:len(data)-1]) == "-" {
 			*ct = CustomTime{}
@@ -107,6 +128,14 @@ func (ct *CustomTime) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf(`CustomTime: parsing "%s": unknown value`, string(data))
 }
 
+func (ct CustomTime) MarshalJSON() ([]byte, error) {
+	t := time.Time(ct)
+	if t.IsZero() {
+		return []byte(`"-"`), nil
+	}
+	return json.Marshal(t.Format("2006-01-02 15:04:05"))
+}
+
 func BoolToInt(v bool) int {
 	if v {
 		return 1
@@ -0,0 +1,308 @@
+package tehnomir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	WATCH_INTERVAL = time.Minute
+	WATCH_OVERLAP  = 5 * time.Minute
+	WATCH_BUFFER   = 64
+
+	API_TIMEZONE = "Europe/Kyiv"
+)
+
+// PositionChanged событие смены статуса позиции заказа.
+// StatusChangedDate нулевая, если API не отдал дату изменения.
+type PositionChanged struct {
+	OrderID           int
+	OrderNumber       string
+	OrderPositionID   int
+	StatusID          PositionStatusID
+	Status            string
+	Quantity          int
+	StatusChangedDate time.Time
+	Position          Position
+}
+
+func (e PositionChanged) key() changeKey {
+	return changeKey{
+		positionID: e.OrderPositionID,
+		statusID:   e.StatusID,
+		changed:    e.StatusChangedDate.UnixNano(),
+	}
+}
+
+type changeKey struct {
+	positionID int
+	statusID   PositionStatusID
+	changed    int64
+}
+
+// CheckpointStore хранит отметку последнего обработанного изменения (StatusChangedDate).
+// Load возвращает нулевое время, если отметки еще нет.
+type CheckpointStore interface {
+	Load(ctx context.Context) (time.Time, error)
+	Save(ctx context.Context, t time.Time) error
+}
+
+type MemoryCheckpoint struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (m *MemoryCheckpoint) Load(context.Context) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.t, nil
+}
+
+func (m *MemoryCheckpoint) Save(_ context.Context, t time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.t = t
+	return nil
+}
+
+// FileCheckpoint хранит отметку в JSON файле.
+type FileCheckpoint struct {
+	path string
+}
+
+func NewFileCheckpoint(path string) *FileCheckpoint {
+	return &FileCheckpoint{path: path}
+}
+
+func (f *FileCheckpoint) Load(context.Context) (time.Time, error) {
+	var cp struct {
+		HighWaterMark time.Time `json:"highWaterMark"`
+	}
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return time.Time{}, err
+	}
+	return cp.HighWaterMark, nil
+}
+
+func (f *FileCheckpoint) Save(_ context.Context, t time.Time) error {
+	data, err := json.Marshal(struct {
+		HighWaterMark time.Time `json:"highWaterMark"`
+	}{t})
+	if err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
+
+// Watcher опрашивает ChangedPositions и доставляет новые смены статусов.
+// Каждый запрос начинается с отметки минус Overlap, чтобы не терять изменения
+// при расхождении часов, повторы отсеиваются по (OrderPositionID, StatusID, StatusChangedDate).
+// Доставка "как минимум один раз": после перезапуска события из окна Overlap
+// могут прийти повторно.
+//
+// API отдает StatusChangedDate по местному времени Техномира без зоны, CustomTime
+// разбирает его как UTC. Отметка хранится в том же виде: время по часам API с зоной UTC.
+type Watcher struct {
+	Interval time.Duration
+	Overlap  time.Duration
+	// Since отметка для первого запуска, если в хранилище ее нет. По умолчанию - время запуска.
+	// Переводится в часовой пояс Location.
+	Since time.Time
+	// Location часовой пояс API, по умолчанию API_TIMEZONE.
+	Location *time.Location
+	// OnError вызывается при ошибках опроса и сохранения отметки, опрос продолжается.
+	OnError func(error)
+
+	client *Client
+	store  CheckpointStore
+	loaded bool
+	hwm    time.Time
+	seen   map[changeKey]time.Time
+}
+
+// NewWatcher создает Watcher, store nil - отметка хранится только в памяти.
+func NewWatcher(client *Client, store CheckpointStore) *Watcher {
+	if store == nil {
+		store = &MemoryCheckpoint{}
+	}
+	return &Watcher{
+		Interval: WATCH_INTERVAL,
+		Overlap:  WATCH_OVERLAP,
+		Location: apiLocation(),
+		client:   client,
+		store:    store,
+		seen:     make(map[changeKey]time.Time),
+	}
+}
+
+// Run опрашивает API до отмены ctx и вызывает handler для каждого события по порядку.
+// Если handler вернул ошибку, отметка не сдвигается дальше последнего успешно
+// обработанного события и оставшиеся события будут доставлены при следующем опросе.
+func (w *Watcher) Run(ctx context.Context, handler func(PositionChanged) error) error {
+	if err := w.load(ctx); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(durationOrDefault(w.Interval, WATCH_INTERVAL))
+	defer ticker.Stop()
+	for {
+		if err := w.Poll(ctx, handler); err != nil && ctx.Err() == nil && w.OnError != nil {
+			w.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Events как Run, но отдает события в канал, который закрывается при отмене ctx.
+func (w *Watcher) Events(ctx context.Context) <-chan PositionChanged {
+	out := make(chan PositionChanged, WATCH_BUFFER)
+	go func() {
+		defer close(out)
+		err := w.Run(ctx, func(e PositionChanged) error {
+			select {
+			case out <- e:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil && ctx.Err() == nil && w.OnError != nil {
+			w.OnError(err)
+		}
+	}()
+	return out
+}
+
+func (w *Watcher) load(ctx context.Context) error {
+	if w.loaded {
+		return nil
+	}
+	hwm, err := w.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if hwm.IsZero() {
+		since := w.Since
+		if since.IsZero() {
+			since = time.Now()
+		}
+		hwm = apiWallClock(since, w.Location)
+	}
+	w.hwm = hwm
+	w.loaded = true
+	return nil
+}
+
+func apiLocation() *time.Location {
+	loc, err := time.LoadLocation(API_TIMEZONE)
+	if err != nil {
+		// нет базы часовых поясов, летнее время не учитывается
+		return time.FixedZone("EET", 2*60*60)
+	}
+	return loc
+}
+
+// apiWallClock переводит t в часы API и помечает зоной UTC, как это делает CustomTime.
+func apiWallClock(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = apiLocation()
+	}
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// Poll выполняет один опрос.
+func (w *Watcher) Poll(ctx context.Context, handler func(PositionChanged) error) error {
+	if err := w.load(ctx); err != nil {
+		return err
+	}
+	from := w.hwm.Add(-w.Overlap)
+	res, err := w.client.ChangedPositionsCtx(ctx, from)
+	if err != nil {
+		return err
+	}
+	events := changesFrom(res.Positions)
+	hwm := w.hwm
+	var herr error
+	for _, e := range events {
+		// без даты ("-") событие передается как есть, но отметку не сдвигает
+		undated := e.StatusChangedDate.IsZero()
+		if !undated && e.StatusChangedDate.Before(from) {
+			continue
+		}
+		if _, ok := w.seen[e.key()]; ok {
+			continue
+		}
+		if herr = handler(e); herr != nil {
+			break
+		}
+		if undated {
+			// запоминается по текущей отметке, иначе prune сразу его забудет
+			w.seen[e.key()] = w.hwm
+			continue
+		}
+		w.seen[e.key()] = e.StatusChangedDate
+		if e.StatusChangedDate.After(hwm) {
+			hwm = e.StatusChangedDate
+		}
+	}
+	if hwm.After(w.hwm) {
+		w.hwm = hwm
+		if err := w.store.Save(ctx, hwm); err != nil {
+			return err
+		}
+	}
+	w.prune()
+	return herr
+}
+
+// prune забывает события, вышедшие за окно Overlap.
+func (w *Watcher) prune() {
+	border := w.hwm.Add(-2 * w.Overlap)
+	for k, t := range w.seen {
+		if t.Before(border) {
+			delete(w.seen, k)
+		}
+	}
+}
+
+func changesFrom(positions []Position) []PositionChanged {
+	var events []PositionChanged
+	for _, p := range positions {
+		for _, s := range p.States {
+			events = append(events, PositionChanged{
+				OrderID:           p.OrderID,
+				OrderNumber:       p.OrderNumber,
+				OrderPositionID:   p.OrderPositionID,
+				StatusID:          s.StatusID,
+				Status:            s.Status,
+				Quantity:          s.Quantity,
+				StatusChangedDate: time.Time(s.StatusChangedDate),
+				Position:          p,
+			})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].StatusChangedDate.Before(events[j].StatusChangedDate)
+	})
+	return events
+}
@@ -0,0 +1,367 @@
+package tehnomir
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	WEBHOOK_EVENT_POSITION_CHANGED = "position.changed"
+	WEBHOOK_SIGNATURE_HEADER       = "X-Tehnomir-Signature"
+	WEBHOOK_TIMESTAMP_HEADER       = "X-Tehnomir-Timestamp"
+	WEBHOOK_EVENT_HEADER           = "X-Tehnomir-Event"
+	WEBHOOK_TIMEOUT                = 10 * time.Second
+	WEBHOOK_TOLERANCE              = 5 * time.Minute
+	WEBHOOK_QUEUE_SIZE             = 1024
+)
+
+var (
+	ErrWebhookClosed    = errors.New("webhook dispatcher closed")
+	ErrWebhookQueueFull = errors.New("webhook queue full")
+)
+
+// WebhookSubscriber получатель событий. Пустые StatusIDs/PriceLogos - без фильтра.
+type WebhookSubscriber struct {
+	Name       string             `json:"name"`
+	URL        string             `json:"url"`
+	Secret     string             `json:"secret"`
+	StatusIDs  []PositionStatusID `json:"statusIds,omitempty"`
+	PriceLogos []string           `json:"priceLogos,omitempty"`
+}
+
+func (s *WebhookSubscriber) accepts(e PositionChanged) bool {
+	if len(s.StatusIDs) > 0 {
+		ok := false
+		for _, id := range s.StatusIDs {
+			ok = ok || id == e.StatusID
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(s.PriceLogos) > 0 {
+		for _, l := range s.PriceLogos {
+			if strings.EqualFold(l, e.Position.PriceLogo) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// WebhookPayload тело запроса к получателю.
+type WebhookPayload struct {
+	ID                string           `json:"id"` // стабилен для одного события, для дедупликации у получателя
+	Event             string           `json:"event"`
+	OrderID           int              `json:"orderId"`
+	OrderNumber       string           `json:"orderNumber"`
+	OrderPositionID   int              `json:"orderPositionId"`
+	StatusID          PositionStatusID `json:"statusId"`
+	Status            string           `json:"status"`
+	Quantity          int              `json:"quantity"`
+	StatusChangedDate time.Time        `json:"statusChangedDate"`
+	Position          Position         `json:"position"`
+}
+
+func newWebhookPayload(e PositionChanged) WebhookPayload {
+	return WebhookPayload{
+		ID:                fmt.Sprintf("%d-%d-%d", e.OrderPositionID, e.StatusID, e.StatusChangedDate.Unix()),
+		Event:             WEBHOOK_EVENT_POSITION_CHANGED,
+		OrderID:           e.OrderID,
+		OrderNumber:       e.OrderNumber,
+		OrderPositionID:   e.OrderPositionID,
+		StatusID:          e.StatusID,
+		Status:            e.Status,
+		Quantity:          e.Quantity,
+		StatusChangedDate: e.StatusChangedDate,
+		Position:          e.Position,
+	}
+}
+
+// SignWebhook подпись тела: hex HMAC-SHA256 от "<timestamp>.<body>" с ключом secret.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook проверяет подпись на стороне получателя. Запросы с меткой времени,
+// отличающейся от текущего времени больше чем на tolerance (0 - WEBHOOK_TOLERANCE),
+// отклоняются как повторные.
+func VerifyWebhook(secret string, timestamp int64, body []byte, signature string, tolerance time.Duration) bool {
+	if tolerance <= 0 {
+		tolerance = WEBHOOK_TOLERANCE
+	}
+	age := time.Since(time.Unix(timestamp, 0))
+	if age > tolerance || age < -tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(SignWebhook(secret, timestamp, body)), []byte(signature))
+}
+
+type deadLetter struct {
+	Subscriber string         `json:"subscriber"`
+	URL        string         `json:"url"`
+	Payload    WebhookPayload `json:"payload"`
+	Error      string         `json:"error"`
+	Attempts   int            `json:"attempts"`
+	FailedAt   time.Time      `json:"failedAt"`
+}
+
+// WebhookDispatcher рассылает события смены статусов позиций подписчикам с подписью HMAC.
+// У каждого подписчика своя очередь и горутина доставки, поэтому Dispatch не ждет
+// ответов и недоступный подписчик не задерживает ни ленту событий, ни других подписчиков.
+// Недоставленные после всех повторов события и события, не поместившиеся в очередь,
+// дописываются в DeadLetterPath (JSON Lines), если он задан, иначе отбрасываются.
+// События в очередях живут только в памяти, перед выходом нужно вызвать Close.
+type WebhookDispatcher struct {
+	Retry          RetryPolicy
+	DeadLetterPath string
+	// OnError вызывается для каждого недоставленного события и ошибки записи в dead-letter файл.
+	OnError func(error)
+
+	client  *http.Client
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	closed  bool
+	workers []*webhookWorker
+	dlMu    sync.Mutex
+}
+
+type webhookJob struct {
+	payload WebhookPayload
+	body    []byte
+}
+
+type webhookWorker struct {
+	sub   WebhookSubscriber
+	queue chan webhookJob
+}
+
+// NewWebhookDispatcher создает рассыльщик, hc nil - http.Client с таймаутом WEBHOOK_TIMEOUT.
+func NewWebhookDispatcher(hc *http.Client, deadLetterPath string, subs ...WebhookSubscriber) *WebhookDispatcher {
+	if hc == nil {
+		hc = &http.Client{Timeout: WEBHOOK_TIMEOUT}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &WebhookDispatcher{
+		Retry:          DefaultRetryPolicy(),
+		DeadLetterPath: deadLetterPath,
+		client:         hc,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+	for _, s := range subs {
+		d.Subscribe(s)
+	}
+	return d
+}
+
+// Subscribe добавляет подписчика и запускает его горутину доставки.
+func (d *WebhookDispatcher) Subscribe(s WebhookSubscriber) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	w := &webhookWorker{sub: s, queue: make(chan webhookJob, WEBHOOK_QUEUE_SIZE)}
+	d.workers = append(d.workers, w)
+	d.wg.Add(1)
+	go d.run(w)
+}
+
+// Handler возвращает функцию для Watcher.Run.
+func (d *WebhookDispatcher) Handler(ctx context.Context) func(PositionChanged) error {
+	return func(e PositionChanged) error {
+		return d.Dispatch(ctx, e)
+	}
+}
+
+// Dispatch ставит событие в очереди подходящих подписчиков и сразу возвращается.
+// Если очередь подписчика заполнена, событие для него сразу уходит в dead-letter.
+// Ошибки доставки передаются в OnError и не возвращаются, иначе Watcher не сдвинет
+// отметку и будет повторять событие для всех подписчиков. Возвращается ошибка ctx
+// или ErrWebhookClosed после Close.
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, e PositionChanged) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	payload := newWebhookPayload(e)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.report(err)
+		return nil
+	}
+	job := webhookJob{payload: payload, body: body}
+
+	var overflow []WebhookSubscriber
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return ErrWebhookClosed
+	}
+	for _, w := range d.workers {
+		if !w.sub.accepts(e) {
+			continue
+		}
+		select {
+		case w.queue <- job:
+		default:
+			overflow = append(overflow, w.sub)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, s := range overflow {
+		d.fail(s, payload, ErrWebhookQueueFull, 0)
+	}
+	return nil
+}
+
+// Close перестает принимать события и ждет, пока подписчики получат уже поставленные
+// в очередь. Если ctx завершится раньше, текущие доставки прерываются, а оставшиеся
+// события уходят в dead-letter.
+func (d *WebhookDispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, w := range d.workers {
+			close(w.queue)
+		}
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (d *WebhookDispatcher) run(w *webhookWorker) {
+	defer d.wg.Done()
+	for job := range w.queue {
+		if err := d.ctx.Err(); err != nil {
+			d.fail(w.sub, job.payload, err, 0)
+			continue
+		}
+		attempts, err := d.deliver(d.ctx, &w.sub, job.body)
+		if err != nil {
+			d.fail(w.sub, job.payload, err, attempts)
+		}
+	}
+}
+
+func (d *WebhookDispatcher) fail(s WebhookSubscriber, payload WebhookPayload, cause error, attempts int) {
+	d.report(fmt.Errorf("webhook %s: %w", s.Name, cause))
+	if err := d.deadLetter(s, payload, cause, attempts); err != nil {
+		d.report(fmt.Errorf("webhook %s: dead letter: %w", s.Name, err))
+	}
+}
+
+func (d *WebhookDispatcher) report(err error) {
+	if d.OnError != nil {
+		d.OnError(err)
+	}
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, s *WebhookSubscriber, body []byte) (int, error) {
+	attempts := d.Retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		retry, err := d.post(ctx, s, body)
+		if err == nil || !retry || attempt >= attempts {
+			return attempt, err
+		}
+		if err := sleepCtx(ctx, d.Retry.backoff(attempt)); err != nil {
+			return attempt, err
+		}
+	}
+}
+
+func (d *WebhookDispatcher) post(ctx context.Context, s *WebhookSubscriber, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WEBHOOK_EVENT_HEADER, WEBHOOK_EVENT_POSITION_CHANGED)
+	req.Header.Set(WEBHOOK_TIMESTAMP_HEADER, strconv.FormatInt(ts, 10))
+	if s.Secret != "" {
+		req.Header.Set(WEBHOOK_SIGNATURE_HEADER, SignWebhook(s.Secret, ts, body))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil && d.Retry.retryable(err), err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("%s: http %d", s.URL, resp.StatusCode)
+	for _, st := range d.Retry.RetryStatuses {
+		if resp.StatusCode == st {
+			return true, err
+		}
+	}
+	return false, err
+}
+
+func (d *WebhookDispatcher) deadLetter(s WebhookSubscriber, payload WebhookPayload, cause error, attempts int) error {
+	if d.DeadLetterPath == "" {
+		return nil
+	}
+	line, err := json.Marshal(deadLetter{
+		Subscriber: s.Name,
+		URL:        s.URL,
+		Payload:    payload,
+		Error:      cause.Error(),
+		Attempts:   attempts,
+		FailedAt:   time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	d.dlMu.Lock()
+	defer d.dlMu.Unlock()
+	f, err := os.OpenFile(d.DeadLetterPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,57 @@
+package tehnomir
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookDispatchDoesNotWaitForSubscribers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	got := make(chan string, 1)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case got <- r.Header.Get(WEBHOOK_EVENT_HEADER):
+		default:
+		}
+	}))
+	defer fast.Close()
+
+	d := NewWebhookDispatcher(nil, "",
+		WebhookSubscriber{Name: "slow", URL: slow.URL},
+		WebhookSubscriber{Name: "fast", URL: fast.URL},
+	)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := d.Dispatch(context.Background(), PositionChanged{OrderPositionID: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Dispatch took %v", elapsed)
+	}
+	select {
+	case ev := <-got:
+		if ev != WEBHOOK_EVENT_POSITION_CHANGED {
+			t.Errorf("event = %q", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fast subscriber blocked by slow one")
+	}
+
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Dispatch(context.Background(), PositionChanged{}); err != ErrWebhookClosed {
+		t.Errorf("Dispatch after Close = %v, want ErrWebhookClosed", err)
+	}
+}